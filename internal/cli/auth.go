@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// authFlags holds the OpenBao authentication and TLS flags shared by the
+// commands that connect to OpenBao.
+type authFlags struct {
+	method       string
+	mount        string
+	token        string
+	tokenFile    string
+	roleID       string
+	secretIDFile string
+	role         string
+	jwtFile      string
+	username     string
+	passwordFile string
+	caCert       string
+	clientCert   string
+	clientKey    string
+}
+
+// register adds the authentication flags to cmd.
+func (f *authFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.method, "auth-method", openbao.AuthToken, "OpenBao auth method: token, approle, kubernetes, userpass, cert, jwt")
+	cmd.Flags().StringVar(&f.mount, "auth-mount", "", "Auth method mount path (default: the method name)")
+	cmd.Flags().StringVar(&f.token, "openbao-token", "", "OpenBao authentication token (prefer --openbao-token-file, VAULT_TOKEN or BAO_TOKEN)")
+	cmd.Flags().StringVar(&f.tokenFile, "openbao-token-file", "", "Read the OpenBao token from a file ('-' for stdin)")
+	cmd.Flags().StringVar(&f.roleID, "auth-role-id", "", "AppRole role ID")
+	cmd.Flags().StringVar(&f.secretIDFile, "auth-secret-id-file", "", "Read the AppRole secret ID from a file ('-' for stdin)")
+	cmd.Flags().StringVar(&f.role, "auth-role", "", "Role to log in as (kubernetes, jwt, cert)")
+	cmd.Flags().StringVar(&f.jwtFile, "auth-jwt-file", "", "Read the JWT from a file ('-' for stdin; kubernetes default: the service account token)")
+	cmd.Flags().StringVar(&f.username, "auth-username", "", "Userpass username")
+	cmd.Flags().StringVar(&f.passwordFile, "auth-password-file", "", "Read the userpass password from a file ('-' for stdin; prompted if omitted)")
+	cmd.Flags().StringVar(&f.caCert, "ca-cert", "", "PEM-encoded CA certificate to verify OpenBao with")
+	cmd.Flags().StringVar(&f.clientCert, "client-cert", "", "PEM-encoded TLS client certificate (required for cert auth)")
+	cmd.Flags().StringVar(&f.clientKey, "client-key", "", "PEM-encoded TLS client key (required for cert auth)")
+}
+
+// authMethod builds the auth method selected by the flags, reading any
+// credential files.
+func (f *authFlags) authMethod() (openbao.AuthMethod, error) {
+	switch f.method {
+	case openbao.AuthToken:
+		token, err := openbao.ResolveToken(f.token, f.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("no OpenBao token: set --openbao-token-file, VAULT_TOKEN, BAO_TOKEN or --openbao-token")
+		}
+		return &openbao.TokenAuth{Token: token}, nil
+
+	case openbao.AuthAppRole:
+		if f.roleID == "" {
+			return nil, fmt.Errorf("--auth-role-id is required for approle auth")
+		}
+		auth := &openbao.AppRoleAuth{Mount: f.mount, RoleID: f.roleID}
+		if f.secretIDFile != "" {
+			secretID, err := openbao.ReadCredentialFile(f.secretIDFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret ID: %w", err)
+			}
+			auth.SecretID = secretID
+		}
+		return auth, nil
+
+	case openbao.AuthKubernetes:
+		if f.role == "" {
+			return nil, fmt.Errorf("--auth-role is required for kubernetes auth")
+		}
+		return &openbao.KubernetesAuth{Mount: f.mount, Role: f.role, JWTPath: f.jwtFile}, nil
+
+	case openbao.AuthUserpass:
+		if f.username == "" {
+			return nil, fmt.Errorf("--auth-username is required for userpass auth")
+		}
+		password, err := f.password()
+		if err != nil {
+			return nil, err
+		}
+		return &openbao.UserpassAuth{Mount: f.mount, Username: f.username, Password: password}, nil
+
+	case openbao.AuthCert:
+		if f.clientCert == "" || f.clientKey == "" {
+			return nil, fmt.Errorf("--client-cert and --client-key are required for cert auth")
+		}
+		return &openbao.CertAuth{Mount: f.mount, Role: f.role}, nil
+
+	case openbao.AuthJWT:
+		if f.jwtFile == "" {
+			return nil, fmt.Errorf("--auth-jwt-file is required for jwt auth")
+		}
+		jwt, err := openbao.ReadCredentialFile(f.jwtFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT: %w", err)
+		}
+		return &openbao.JWTAuth{Mount: f.mount, Role: f.role, JWT: jwt}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown auth method: %s (expected token, approle, kubernetes, userpass, cert or jwt)", f.method)
+	}
+}
+
+// password reads the userpass password from --auth-password-file or
+// prompts for it.
+func (f *authFlags) password() (string, error) {
+	if f.passwordFile != "" {
+		password, err := openbao.ReadCredentialFile(f.passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return password, nil
+	}
+
+	var password string
+	prompt := &survey.Password{Message: fmt.Sprintf("Password for %s:", f.username)}
+	if err := survey.AskOne(prompt, &password, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return password, nil
+}
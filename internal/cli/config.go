@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/config"
+)
+
+var (
+	configFile    string
+	configProfile string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default: $"+config.EnvConfig+" or $XDG_CONFIG_HOME/openbao-secrets-importer/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&configProfile, "profile", "", "Config file profile to use (default: $"+config.EnvProfile+" or the file's default_profile)")
+	rootCmd.PersistentPreRunE = applyConfig
+}
+
+// applyConfig sets every flag of cmd that was not given on the command line
+// from its OPENBAO_SECRETS_IMPORTER_* environment variable or, failing that,
+// from the selected config file profile. It runs before required flags are
+// checked, so a profile can supply them.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == "config" || f.Name == "profile" || f.Name == "help" {
+			return
+		}
+		// OPENBAO_SECRETS_IMPORTER_PASSPHRASE holds the passphrase itself,
+		// not a value for --passphrase
+		if config.EnvName(f.Name) == passphraseEnv {
+			return
+		}
+		if value, ok := os.LookupEnv(config.EnvName(f.Name)); ok {
+			// Only comma-separated flags are split; a repeatable flag such
+			// as --header takes the whole value, which may contain commas
+			values := []string{value}
+			if _, ok := f.Value.(pflag.SliceValue); ok && f.Value.Type() != "stringArray" {
+				values = strings.Split(value, ",")
+			}
+			if setErr := setFlag(cmd, f, values); setErr != nil {
+				err = fmt.Errorf("invalid %s: %w", config.EnvName(f.Name), setErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	settings, source, err := loadProfile()
+	if err != nil || settings == nil {
+		return err
+	}
+
+	known := knownFlags(rootCmd)
+	for _, key := range settings.Keys() {
+		if !known[key] || key == "config" || key == "profile" {
+			return fmt.Errorf("%s: unknown setting %q", source, key)
+		}
+
+		f := cmd.Flags().Lookup(key)
+		if f == nil || f.Changed {
+			continue
+		}
+		values, err := settings.Values(key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := setFlag(cmd, f, values); err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", source, key, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Using %s\n", source)
+	return nil
+}
+
+// loadProfile returns the settings of the selected profile and a description
+// of where they came from, or nil if no profile is selected.
+func loadProfile() (config.Settings, string, error) {
+	path := config.Find(configFile)
+	profile := configProfile
+	if profile == "" {
+		profile = os.Getenv(config.EnvProfile)
+	}
+
+	if path == "" {
+		if profile != "" {
+			return nil, "", fmt.Errorf("profile %q selected but no config file found (set --config or %s)", profile, config.EnvConfig)
+		}
+		return nil, "", nil
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if profile == "" {
+		profile = cfg.DefaultProfile
+	}
+	if profile == "" {
+		return nil, "", nil
+	}
+
+	settings, err := cfg.Profile(profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("config file %s: %w", path, err)
+	}
+	return settings, fmt.Sprintf("profile %s from %s", profile, path), nil
+}
+
+// setFlag sets a flag as if values had been given on the command line.
+// List flags are replaced by values; other flags take a single value.
+func setFlag(cmd *cobra.Command, f *pflag.Flag, values []string) error {
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		if err := slice.Replace(values); err != nil {
+			return err
+		}
+		f.Changed = true
+		return nil
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("expected a single value")
+	}
+	return cmd.Flags().Set(f.Name, values[0])
+}
+
+// knownFlags returns the names of the flags of cmd and all its subcommands.
+func knownFlags(cmd *cobra.Command) map[string]bool {
+	known := make(map[string]bool)
+	var visit func(*cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.Flags().VisitAll(func(f *pflag.Flag) { known[f.Name] = true })
+		c.PersistentFlags().VisitAll(func(f *pflag.Flag) { known[f.Name] = true })
+		for _, child := range c.Commands() {
+			visit(child)
+		}
+	}
+	visit(cmd)
+	return known
+}
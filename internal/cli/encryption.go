@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/schema"
+)
+
+const (
+	// passphraseEnv supplies the passphrase for scrypt-encrypted export files.
+	passphraseEnv = "OPENBAO_SECRETS_IMPORTER_PASSPHRASE"
+
+	// identityEnv points to an age identity file used to decrypt export files.
+	identityEnv = "OPENBAO_SECRETS_IMPORTER_IDENTITY"
+)
+
+// parseRecipients parses --encrypt-to values. Each value is either an age
+// recipient (age1...) or the path to a recipients file.
+func parseRecipients(values []string) ([]age.Recipient, []string, error) {
+	var recipients []age.Recipient
+	var names []string
+
+	for _, value := range values {
+		var parsed []age.Recipient
+		var err error
+
+		if strings.HasPrefix(value, "age1") {
+			parsed, err = age.ParseRecipients(strings.NewReader(value))
+		} else {
+			var f *os.File
+			f, err = os.Open(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to open recipients file: %w", err)
+			}
+			parsed, err = age.ParseRecipients(f)
+			f.Close()
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid recipient %s: %w", value, err)
+		}
+
+		for _, r := range parsed {
+			recipients = append(recipients, r)
+			if s, ok := r.(fmt.Stringer); ok {
+				names = append(names, s.String())
+			}
+		}
+	}
+
+	return recipients, names, nil
+}
+
+// exportEncryption returns the age recipients and encryption info for export
+// based on the --encrypt-to and --passphrase flags. It returns nil recipients
+// when no encryption was requested.
+func exportEncryption(encryptTo []string, usePassphrase bool) ([]age.Recipient, schema.EncryptionInfo, error) {
+	if len(encryptTo) > 0 && usePassphrase {
+		return nil, schema.EncryptionInfo{}, fmt.Errorf("--encrypt-to and --passphrase cannot be used together")
+	}
+
+	if usePassphrase {
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return nil, schema.EncryptionInfo{}, err
+		}
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, schema.EncryptionInfo{}, fmt.Errorf("invalid passphrase: %w", err)
+		}
+		return []age.Recipient{r}, schema.EncryptionInfo{Method: schema.EncryptionMethodPassphrase}, nil
+	}
+
+	if len(encryptTo) == 0 {
+		return nil, schema.EncryptionInfo{}, nil
+	}
+
+	recipients, names, err := parseRecipients(encryptTo)
+	if err != nil {
+		return nil, schema.EncryptionInfo{}, err
+	}
+
+	return recipients, schema.EncryptionInfo{
+		Method:     schema.EncryptionMethodAge,
+		Recipients: names,
+	}, nil
+}
+
+// exportDecrypter returns a schema.Decrypter that loads age identities from
+// the given files (or identityEnv), or asks for the passphrase of
+// scrypt-encrypted files.
+func exportDecrypter(identityFiles []string) schema.Decrypter {
+	return func(info *schema.EncryptionInfo) ([]age.Identity, error) {
+		if info.Method == schema.EncryptionMethodPassphrase {
+			passphrase, err := readPassphrase(false)
+			if err != nil {
+				return nil, err
+			}
+			identity, err := age.NewScryptIdentity(passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("invalid passphrase: %w", err)
+			}
+			return []age.Identity{identity}, nil
+		}
+
+		files := identityFiles
+		if len(files) == 0 {
+			if env := os.Getenv(identityEnv); env != "" {
+				files = []string{env}
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("export file is encrypted to %d recipient(s); provide --identity or %s",
+				len(info.Recipients), identityEnv)
+		}
+
+		var identities []age.Identity
+		for _, path := range files {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open identity file: %w", err)
+			}
+			ids, err := age.ParseIdentities(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("invalid identity file %s: %w", path, err)
+			}
+			identities = append(identities, ids...)
+		}
+
+		return identities, nil
+	}
+}
+
+// readPassphrase reads the export passphrase from passphraseEnv or prompts
+// for it. When confirm is set the passphrase must be entered twice.
+func readPassphrase(confirm bool) (string, error) {
+	if env := os.Getenv(passphraseEnv); env != "" {
+		return env, nil
+	}
+
+	var passphrase string
+	if err := survey.AskOne(&survey.Password{Message: "Passphrase:"}, &passphrase, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if confirm {
+		var again string
+		if err := survey.AskOne(&survey.Password{Message: "Confirm passphrase:"}, &again, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
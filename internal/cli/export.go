@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 
+	"filippo.io/age"
 	"github.com/spf13/cobra"
 
 	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/schema"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
-	"github.com/GlueOps/openbao-secrets-importer/pkg/source/aws"
 )
 
 var exportCmd = &cobra.Command{
@@ -28,19 +29,42 @@ Examples:
     --include "prod/**" --exclude "**/temp/*" \
     --output secrets.json
 
+  # Include every retained previous version of each secret
+  openbao-secrets-importer export --source aws-secrets-manager --output secrets.json \
+    --include-versions
+
+  # Rename paths on the way out
+  openbao-secrets-importer export --source aws-secrets-manager --output secrets.json \
+    --rewrite 'regex:^prod/(.*)$=>production/$1' --rewrite sanitize
+
+  # Normalize key names while exporting
+  openbao-secrets-importer export --source aws-secrets-manager --output secrets.json \
+    --transform rename:DB_PASS=password,DB_USER=username --transform drop:_comment
+
+  # Encrypt the export file to age recipients
+  openbao-secrets-importer export --source aws-secrets-manager --output secrets.json \
+    --encrypt-to age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+
   # Dry run to preview without writing
   openbao-secrets-importer export --source aws-secrets-manager --output secrets.json --dry-run`,
 	RunE: runExport,
 }
 
 var (
-	exportSource     string
-	exportOutput     string
-	exportIncludes   []string
-	exportExcludes   []string
-	exportRegion     string
-	exportDryRun     bool
-	exportDefaultKey string
+	exportSource          string
+	exportOutput          string
+	exportIncludes        []string
+	exportExcludes        []string
+	exportRegion          string
+	exportDryRun          bool
+	exportDefaultKey      string
+	exportEncryptTo       []string
+	exportPassphrase      bool
+	exportIncludeVersions bool
+	exportRewrite         rewriteFlags
+	exportTransform       transformFlags
+	exportOpts            []string
+	exportRetry           retryFlags
 )
 
 func init() {
@@ -51,6 +75,13 @@ func init() {
 	exportCmd.Flags().StringVar(&exportRegion, "region", "", "AWS region (for aws-secrets-manager source)")
 	exportCmd.Flags().BoolVar(&exportDryRun, "dry-run", false, "Preview export without writing to file")
 	exportCmd.Flags().StringVar(&exportDefaultKey, "default-key", "value", "Key name for non-JSON secrets (plain text, binary)")
+	exportCmd.Flags().StringArrayVar(&exportOpts, "source-opt", []string{}, "Source-specific option (can be specified multiple times, format: 'key=value')")
+	exportCmd.Flags().StringArrayVar(&exportEncryptTo, "encrypt-to", []string{}, "Encrypt to an age recipient or recipients file (can be specified multiple times)")
+	exportCmd.Flags().BoolVar(&exportPassphrase, "passphrase", false, "Encrypt with a passphrase (read from "+passphraseEnv+" or prompted)")
+	exportCmd.Flags().BoolVar(&exportIncludeVersions, "include-versions", false, "Export the previous versions of each secret (e.g., AWSPREVIOUS) as well as the current one")
+	exportRewrite.register(exportCmd)
+	exportTransform.register(exportCmd)
+	exportRetry.register(exportCmd, false, true)
 
 	exportCmd.MarkFlagRequired("source")
 	exportCmd.MarkFlagRequired("output")
@@ -61,23 +92,31 @@ func init() {
 func runExport(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Get the source
-	src, err := source.Get(exportSource)
+	retryer, err := exportRetry.sourceRetryer()
 	if err != nil {
-		return fmt.Errorf("failed to get source: %w", err)
+		return err
 	}
 
-	// Configure the source
-	opts := make(map[string]interface{})
-	if exportRegion != "" {
-		opts["region"] = exportRegion
+	src, err := configureSource(ctx, exportSource, exportRegion, exportDefaultKey, exportOpts)
+	if err != nil {
+		return err
 	}
-	if exportDefaultKey != "" {
-		opts["non_json_key"] = exportDefaultKey
+	if exportIncludeVersions {
+		src, err = source.WithVersions(src)
+		if err != nil {
+			return err
+		}
 	}
+	src = source.WithRetry(src, retryer)
 
-	if err := src.Configure(ctx, opts); err != nil {
-		return fmt.Errorf("failed to configure source: %w", err)
+	// Resolve encryption keys before fetching any secret values
+	var recipients []age.Recipient
+	var encryption schema.EncryptionInfo
+	if !exportDryRun {
+		recipients, encryption, err = exportEncryption(exportEncryptTo, exportPassphrase)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Create path filter
@@ -86,22 +125,31 @@ func runExport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid filter pattern: %w", err)
 	}
 
+	rules, err := exportRewrite.load()
+	if err != nil {
+		return err
+	}
+	transforms, err := exportTransform.load()
+	if err != nil {
+		return err
+	}
+
 	// Create export file
 	exportFile := schema.NewExportFile(src.Name())
 	exportFile.Metadata.IncludePatterns = exportIncludes
 	exportFile.Metadata.ExcludePatterns = exportExcludes
 
-	// Add region for AWS source
-	if awsSrc, ok := src.(*aws.Source); ok {
-		exportFile.Metadata.Region = awsSrc.Region()
+	// Add region for regional sources (AWS)
+	if regional, ok := source.Unwrap(src).(interface{ Region() string }); ok {
+		exportFile.Metadata.Region = regional.Region()
 	}
 
 	// List secrets first
 	fmt.Fprintf(os.Stderr, "Listing secrets from %s...\n", src.Name())
 
-	patterns := exportIncludes
-	if len(patterns) == 0 {
-		patterns = []string{"**"}
+	patterns := filter.CombinePatterns(exportIncludes, exportExcludes)
+	if len(exportIncludes) == 0 {
+		patterns = append(patterns, "**")
 	}
 
 	infos, err := src.List(ctx, patterns)
@@ -110,9 +158,11 @@ func runExport(cmd *cobra.Command, args []string) error {
 	}
 
 	// Filter with excludes
+	var filtered []source.SecretInfo
 	var filteredPaths []string
 	for _, info := range infos {
 		if pathFilter.Matches(info.Path) {
+			filtered = append(filtered, info)
 			filteredPaths = append(filteredPaths, info.Path)
 		}
 	}
@@ -124,10 +174,21 @@ func runExport(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintf(os.Stderr, "Found %d secrets to export.\n", len(filteredPaths))
 
+	// Check for collisions before fetching any secret values
+	rewrites, err := rules.RewriteAll(filteredPaths)
+	if err != nil {
+		return err
+	}
+
 	if exportDryRun {
 		fmt.Println("\nDry run - secrets that would be exported:")
 		for _, path := range filteredPaths {
-			fmt.Printf("  %s\n", path)
+			if rewritten := rewrites[path]; rewritten.Path != path {
+				fmt.Printf("  %s -> %s\n", path, rewritten.Path)
+				printFiredRules(rewritten)
+			} else {
+				fmt.Printf("  %s\n", path)
+			}
 		}
 		return nil
 	}
@@ -135,19 +196,44 @@ func runExport(cmd *cobra.Command, args []string) error {
 	// Export secrets
 	fmt.Fprintf(os.Stderr, "Exporting secrets...\n")
 
-	var errCount int
-	for idx, path := range filteredPaths {
-		fmt.Fprintf(os.Stderr, "\r  [%d/%d] Fetching %s...", idx+1, len(filteredPaths), path)
-
-		secret, err := src.Get(ctx, path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "\n  Warning: failed to get secret %s: %v\n", path, err)
+	var (
+		secrets  []*source.Secret
+		errCount int
+	)
+	// Fetch the listed secrets rather than listing the source again
+	secretChan, errChan := source.WithListing(src, filtered).Export(ctx, patterns)
+	drainExport(secretChan, errChan,
+		func(secret *source.Secret) {
+			if _, ok := rewrites[secret.Path]; !ok {
+				// A bulk export lists again; skip what the count and
+				// collision check did not see
+				fmt.Fprintf(os.Stderr, "\n  Warning: skipping %s: created after listing\n", secret.Path)
+				return
+			}
+			if err := transforms.Apply(secret); err != nil {
+				fmt.Fprintf(os.Stderr, "\n  Warning: %v\n", err)
+				errCount++
+				return
+			}
+			secrets = append(secrets, secret)
+			fmt.Fprintf(os.Stderr, "\r  [%d/%d] Fetched %s...", len(secrets), len(filteredPaths), secret.Path)
+		},
+		func(err error) {
+			fmt.Fprintf(os.Stderr, "\n  Warning: %v\n", err)
 			errCount++
-			continue
-		}
+		},
+	)
 
+	for _, secret := range secrets {
 		exportFile.AddSecret(secret)
 	}
+
+	renameSecrets(exportFile.Secrets, rewrites)
+
+	// Workers complete in any order; keep the export file stable
+	sort.Slice(exportFile.Secrets, func(i, j int) bool {
+		return exportFile.Secrets[i].Path < exportFile.Secrets[j].Path
+	})
 	fmt.Fprintf(os.Stderr, "\r  Exported %d secrets.                          \n", len(exportFile.Secrets))
 
 	if errCount > 0 {
@@ -155,13 +241,44 @@ func runExport(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write export file
-	if err := exportFile.Write(exportOutput); err != nil {
+	if len(recipients) > 0 {
+		err = exportFile.WriteEncrypted(exportOutput, recipients, encryption)
+	} else {
+		err = exportFile.Write(exportOutput)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to write export file: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "\nExport complete: %s\n", exportOutput)
 	fmt.Fprintf(os.Stderr, "  Total secrets: %d\n", exportFile.Metadata.TotalSecrets)
 	fmt.Fprintf(os.Stderr, "  Schema version: %s\n", exportFile.Version)
+	if exportFile.Metadata.Encryption != nil {
+		fmt.Fprintf(os.Stderr, "  Encryption: %s\n", exportFile.Metadata.Encryption.Method)
+	}
+	printRetryStats(os.Stderr, "Source", retryer.Stats())
 
 	return nil
 }
+
+// drainExport consumes the channels returned by source.Source.Export until
+// both are closed, calling onSecret for every secret and onError for every
+// error reported by the source.
+func drainExport(secretChan <-chan *source.Secret, errChan <-chan error, onSecret func(*source.Secret), onError func(error)) {
+	for secretChan != nil || errChan != nil {
+		select {
+		case secret, ok := <-secretChan:
+			if !ok {
+				secretChan = nil
+				continue
+			}
+			onSecret(secret)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			onError(err)
+		}
+	}
+}
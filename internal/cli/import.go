@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,20 +14,89 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/GlueOps/openbao-secrets-importer/pkg/journal"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/merge"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/plan"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/rewrite"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/schema"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import secrets from a file to OpenBao",
-	Long: `Import secrets from an export file to OpenBao KV v2 secrets engine.
+	Long: `Import secrets from an export file to an OpenBao KV v1 or v2 secrets engine.
 
 Conflict Resolution:
   --skip-existing   Skip secrets that already exist (default)
   --overwrite-all   Overwrite all existing secrets without prompting
   --interactive     Prompt for each secret (Yes/No/Yes-to-all/No-to-all/Abort)
+  --merge           Merge the source keys into existing secrets, keeping keys
+                    that only exist in OpenBao; --merge-policy decides keys
+                    that differ (source, destination or fail); on KV v1
+                    concurrent changes are not detected while merging
+  On KV v2 mounts writes use Check-And-Set, so a secret written concurrently
+  is reported as a conflict instead of being overwritten.
+
+Authentication:
+  The token is read from --openbao-token-file, VAULT_TOKEN, BAO_TOKEN or
+  --openbao-token. --auth-method selects approle, kubernetes, userpass, cert
+  or jwt login instead; tokens are renewed during long imports.
+
+Namespaces:
+  --namespace imports into an OpenBao namespace. --namespace-from fans the
+  export out across child namespaces, taking each secret's namespace from the
+  first segment of its path ("path") or from a tag ("tag:<key>").
+
+Mount Provisioning:
+  --ensure-mount enables a KV v2 secrets engine at --mount if there is none
+  and applies --max-versions, --cas-required and --delete-version-after to
+  its configuration, reporting every change. Settings that are not given are
+  left as they are.
+
+Path Rewriting:
+  --rewrite-file and --rewrite apply ordered rules to every secret path before
+  --path-prefix: regex replacements, glob-to-template mappings, case changes
+  and sanitization of characters OpenBao paths should not contain. The dry run
+  shows the rules that fired for each path; the import fails if two secrets
+  would be written to the same path.
+
+Transforms:
+  --transform-file and --transform reshape the data of every secret before it
+  is written: renaming and dropping keys, flattening nested JSON objects into
+  dotted keys or the reverse, and decoding base64 values. Transforms in a file
+  can be limited to secret paths; they match paths before rewriting.
+
+Version History:
+  --replay-versions writes the previous versions recorded by
+  "export --include-versions" oldest first before the current value, so the
+  KV v2 history mirrors the source. Only as many versions as the mount's
+  max_versions are replayed. Secrets that already have a version history
+  only get the current value written.
+
+Pruning:
+  --prune makes the destination mirror the export file: after a successful
+  import, every secret under --path-prefix that is not in the export file is
+  removed; without --path-prefix that is the whole mount, which needs
+  --prune-whole-mount. --prune-mode delete (default) soft-deletes the current
+  version so it can be undeleted; destroy removes all versions and metadata.
+  --prune-protect globs match destination paths that are never pruned. The
+  secrets to prune are listed and must be confirmed before anything is
+  imported; use "import plan --prune" to review and apply them unattended.
+
+Resuming:
+  Every import records the outcome of each secret in a journal next to the
+  input file (<input>.journal, or --journal). --resume skips secrets that were
+  imported or skipped by an earlier run of the same export file and retries
+  only the ones that failed or were never reached. Failed secrets are
+  overwritten even with --skip-existing, as they may be partly written.
+
+Plan/Apply:
+  "import plan" writes a reviewable plan of the changes an import would make,
+  and "import apply" executes exactly that plan if nothing drifted meanwhile.
 
 Examples:
   # Basic import
@@ -53,6 +123,22 @@ Examples:
     --header "X-Custom-Auth: token" \
     --header "X-Forwarded-For: internal"
 
+  # Log in with AppRole instead of a token
+  openbao-secrets-importer import \
+    --input secrets.json \
+    --openbao-addr https://openbao:8200 \
+    --auth-method approle \
+    --auth-role-id 7f1c... \
+    --auth-secret-id-file /run/secrets/secret-id
+
+  # Resume an interrupted import, retrying only failed secrets
+  openbao-secrets-importer import \
+    --input secrets.json \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --mount secret \
+    --resume
+
   # Interactive mode with per-secret prompts
   openbao-secrets-importer import \
     --input secrets.json \
@@ -64,37 +150,64 @@ Examples:
 }
 
 var (
-	importInput        string
-	importOpenBaoAddr  string
-	importOpenBaoToken string
-	importMount        string
-	importHeaders      []string
-	importPathPrefix   string
-	importSkipExisting bool
-	importOverwriteAll bool
-	importInteractive  bool
-	importParallelism  int
-	importDryRun       bool
-	importTLSSkipVerify bool
+	importInput          string
+	importOpenBaoAddr    string
+	importAuth           authFlags
+	importMount          string
+	importNamespace      string
+	importNamespaceFrom  string
+	importHeaders        []string
+	importPathPrefix     string
+	importSkipExisting   bool
+	importOverwriteAll   bool
+	importInteractive    bool
+	importParallelism    int
+	importDryRun         bool
+	importTLSSkipVerify  bool
+	importIdentities     []string
+	importJournal        string
+	importResume         bool
+	importWriteMetadata  bool
+	importMetadataPrefix string
+	importReplayVersions bool
+	importRewrite        rewriteFlags
+	importTransform      transformFlags
+	importRetry          retryFlags
+	importMountFlags     mountFlags
+	importMerge          mergeFlags
+	importPrune          pruneFlags
 )
 
 func init() {
 	importCmd.Flags().StringVarP(&importInput, "input", "f", "", "Input file path")
 	importCmd.Flags().StringVar(&importOpenBaoAddr, "openbao-addr", "", "OpenBao server address (e.g., https://openbao:8200)")
-	importCmd.Flags().StringVar(&importOpenBaoToken, "openbao-token", "", "OpenBao authentication token")
-	importCmd.Flags().StringVar(&importMount, "mount", "secret", "KV v2 mount path")
+	importAuth.register(importCmd)
+	importCmd.Flags().StringVar(&importMount, "mount", "secret", "KV mount path (v1 or v2, detected automatically)")
+	importCmd.Flags().StringVar(&importNamespace, "namespace", "", "OpenBao namespace to log in to and import into")
+	importCmd.Flags().StringVar(&importNamespaceFrom, "namespace-from", "", "Import each secret into a child namespace taken from its path ('path': first segment) or a tag ('tag:<key>')")
 	importCmd.Flags().StringArrayVar(&importHeaders, "header", []string{}, "Custom HTTP header (can be specified multiple times, format: 'Key: Value')")
 	importCmd.Flags().StringVar(&importPathPrefix, "path-prefix", "", "Prefix to prepend to all secret paths")
 	importCmd.Flags().BoolVar(&importSkipExisting, "skip-existing", true, "Skip secrets that already exist")
 	importCmd.Flags().BoolVar(&importOverwriteAll, "overwrite-all", false, "Overwrite all existing secrets without prompting")
+	importMerge.register(importCmd)
 	importCmd.Flags().BoolVar(&importInteractive, "interactive", false, "Prompt for each secret")
 	importCmd.Flags().IntVar(&importParallelism, "parallelism", 5, "Number of parallel import workers")
 	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Preview import without writing to OpenBao")
 	importCmd.Flags().BoolVar(&importTLSSkipVerify, "tls-skip-verify", false, "Skip TLS certificate verification")
+	importCmd.Flags().StringArrayVar(&importIdentities, "identity", []string{}, "age identity file for encrypted export files (can be specified multiple times)")
+	importCmd.Flags().StringVar(&importJournal, "journal", "", "Checkpoint journal path (default: <input>.journal)")
+	importCmd.Flags().BoolVar(&importResume, "resume", false, "Resume an interrupted import from its journal")
+	importCmd.Flags().BoolVar(&importWriteMetadata, "write-metadata", false, "Write source metadata (ID, description, tags, timestamps) to KV v2 custom_metadata")
+	importCmd.Flags().StringVar(&importMetadataPrefix, "metadata-prefix", openbao.DefaultMetadataPrefix, "Prefix for custom_metadata keys written by --write-metadata")
+	importCmd.Flags().BoolVar(&importReplayVersions, "replay-versions", false, "Replay the previous versions in the export file into the KV v2 version history")
+	importRewrite.register(importCmd)
+	importTransform.register(importCmd)
+	importRetry.register(importCmd, true, false)
+	importMountFlags.register(importCmd)
+	importPrune.register(importCmd)
 
 	importCmd.MarkFlagRequired("input")
 	importCmd.MarkFlagRequired("openbao-addr")
-	importCmd.MarkFlagRequired("openbao-token")
 
 	rootCmd.AddCommand(importCmd)
 }
@@ -110,74 +223,341 @@ const (
 	ConfirmAbort
 )
 
+// importOptions controls how secrets are written to OpenBao. It is shared by
+// the import and migrate commands.
+type importOptions struct {
+	pathPrefix   string
+	skipExisting bool
+	overwriteAll bool
+	parallelism  int
+	journal      *journal.Journal // Records per-path outcomes; nil to disable
+	namespaces   *namespaceRouter // Routes secrets to child namespaces; nil to disable
+
+	writeMetadata  bool   // Write source metadata to custom_metadata
+	metadataPrefix string // Prefix for custom_metadata keys
+	sourceName     string // Source recorded in custom_metadata
+
+	replayVersions bool // Write previous versions before the current one
+
+	mergePolicy merge.Policy // Merge into existing secrets; empty to disable
+
+	retried map[string]bool // Failed or conflicting paths retried by --resume; always overwritten
+}
+
 // ImportResult tracks the result of an import operation.
 type ImportResult struct {
-	Path    string
-	Success bool
-	Skipped bool
-	Error   error
+	Path     string
+	Success  bool
+	Skipped  bool
+	Conflict bool // The secret was written concurrently and the CAS write lost
+	Error    error
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Validate flags
+	if importParallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1")
+	}
 	if importOverwriteAll && importInteractive {
 		return fmt.Errorf("--overwrite-all and --interactive cannot be used together")
 	}
 
-	if importOverwriteAll {
+	if importResume && importInteractive {
+		return fmt.Errorf("--resume and --interactive cannot be used together")
+	}
+
+	namespaces, err := newNamespaceRouter(importNamespaceFrom)
+	if err != nil {
+		return err
+	}
+	if namespaces != nil && importInteractive {
+		return fmt.Errorf("--namespace-from and --interactive cannot be used together")
+	}
+
+	mergePolicy, err := importMerge.load()
+	if err != nil {
+		return err
+	}
+	if mergePolicy != "" && importOverwriteAll {
+		return fmt.Errorf("--merge and --overwrite-all cannot be used together")
+	}
+	if mergePolicy != "" && importReplayVersions {
+		return fmt.Errorf("--merge and --replay-versions cannot be used together")
+	}
+
+	if importOverwriteAll || mergePolicy != "" {
 		importSkipExisting = false
 	}
 
+	mountConfig, err := importMountFlags.config(cmd, importDryRun, importNamespaceFrom)
+	if err != nil {
+		return err
+	}
+
+	prune, err := importPrune.load(cmd, normalizePathPrefix(importPathPrefix))
+	if err != nil {
+		return err
+	}
+	if prune != nil && namespaces != nil {
+		return fmt.Errorf("--prune cannot be used with --namespace-from")
+	}
+	if prune != nil && importInteractive {
+		return fmt.Errorf("--prune and --interactive cannot be used together")
+	}
+
+	rules, err := importRewrite.load()
+	if err != nil {
+		return err
+	}
+	transforms, err := importTransform.load()
+	if err != nil {
+		return err
+	}
+
 	// Read and validate export file
 	fmt.Fprintf(os.Stderr, "Reading export file: %s\n", importInput)
-	export, err := schema.ValidateFile(importInput)
+	export, err := schema.ValidateFileWith(importInput, exportDecrypter(importIdentities))
 	if err != nil {
 		return fmt.Errorf("failed to read/validate export file: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "  Found %d secrets to import\n", len(export.Secrets))
 
-	// Parse custom headers
-	headers, err := openbao.ParseHeaders(importHeaders)
+	if err := transformSecrets(transforms, export.Secrets); err != nil {
+		return err
+	}
+
+	rewrites, err := rules.RewriteAll(secretPaths(export.Secrets))
 	if err != nil {
-		return fmt.Errorf("invalid header: %w", err)
+		return err
 	}
 
 	// Normalize path prefix
 	pathPrefix := normalizePathPrefix(importPathPrefix)
+	keep := keepPaths(export.Secrets, rewrites, pathPrefix)
 
 	if importDryRun {
-		return runDryRun(export, pathPrefix)
+		var client *openbao.Client
+		if mergePolicy != "" || prune != nil {
+			// Previewing a merge or prune needs the destination secrets
+			client, err = connectOpenBao(ctx, importOpenBaoAddr, importMount, importNamespace, importHeaders, importTLSSkipVerify, &importAuth, nil)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+		}
+		if err := runDryRun(ctx, export, rewrites, pathPrefix, namespaces, client, mergePolicy); err != nil {
+			return err
+		}
+		if prune != nil {
+			changes, err := prune.candidates(ctx, client, pathPrefix, keep)
+			if err != nil {
+				return err
+			}
+			printPrune(changes, prune.mode)
+		}
+		return nil
+	}
+	renameSecrets(export.Secrets, rewrites)
+
+	retryer, err := importRetry.openbaoRetryer()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectOpenBao(ctx, importOpenBaoAddr, importMount, importNamespace, importHeaders, importTLSSkipVerify, &importAuth, retryer)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if importMountFlags.ensure {
+		if err := ensureMount(ctx, client, mountConfig); err != nil {
+			return err
+		}
+	}
+
+	opts := importOptions{
+		pathPrefix:     pathPrefix,
+		skipExisting:   importSkipExisting,
+		overwriteAll:   importOverwriteAll,
+		parallelism:    importParallelism,
+		namespaces:     namespaces,
+		writeMetadata:  importWriteMetadata,
+		metadataPrefix: importMetadataPrefix,
+		sourceName:     export.Metadata.Source,
+		replayVersions: importReplayVersions,
+		mergePolicy:    mergePolicy,
+	}
+
+	if err := checkTarget(ctx, client, opts); err != nil {
+		return err
+	}
+	if opts.replayVersions {
+		if err := checkVersionHistory(ctx, client, export.Secrets); err != nil {
+			return err
+		}
+	}
+
+	// Confirm the prune up front so a declined prune imports nothing
+	var pruneChanges []plan.Change
+	if prune != nil {
+		if pruneChanges, err = prune.candidates(ctx, client, pathPrefix, keep); err != nil {
+			return err
+		}
+		printPrune(pruneChanges, prune.mode)
+		if len(pruneChanges) > 0 {
+			if err := prune.confirm(pruneChanges); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Run import
+	if importInteractive {
+		return runInteractiveImport(ctx, client, export.Secrets, opts)
+	}
+
+	journalPath := importJournal
+	if journalPath == "" {
+		journalPath = importInput + ".journal"
+	}
+	digest, err := plan.FileDigest(importInput)
+	if err != nil {
+		return err
+	}
+	header := journal.Header{
+		ExportDigest: digest,
+		Address:      client.Address(),
+		Mount:        client.Mount(),
+		Namespace:    client.Namespace(),
+		PathPrefix:   pathPrefix,
+		StartedAt:    time.Now().UTC(),
+	}
+
+	j, statuses, err := openImportJournal(journalPath, header, importResume)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+
+	opts.journal = j
+	opts.retried = make(map[string]bool)
+
+	var pending []source.Secret
+	for _, secret := range export.Secrets {
+		namespace, destPath, err := namespaces.destination(secret.Path, secret.Metadata.Tags, pathPrefix)
+		if err != nil {
+			pending = append(pending, secret)
+			continue
+		}
+		status, ok := statuses[namespacedPath(namespace, destPath)]
+		if ok && status.Done() {
+			continue
+		}
+		pending = append(pending, secret)
+		if ok {
+			// An earlier attempt may have written part of the secret
+			opts.retried[namespacedPath(namespace, destPath)] = true
+		}
+	}
+	if importResume {
+		fmt.Fprintf(os.Stderr, "  Resuming: %d secrets already done, %d remaining\n",
+			len(export.Secrets)-len(pending), len(pending))
+	}
+
+	work := make(chan source.Secret, len(pending))
+	for _, secret := range pending {
+		work <- secret
+	}
+	close(work)
+
+	// Only prune once every secret in the export file was imported
+	if err := runParallelImport(ctx, client, work, len(pending), opts); err != nil {
+		return err
+	}
+	return prune.run(ctx, client, pruneChanges)
+}
+
+// openImportJournal creates the checkpoint journal for an import, or reopens
+// it when resuming. It returns the last recorded status of every destination
+// path, which is empty for a new journal. A resumed journal must belong to
+// the same export file and target.
+func openImportJournal(path string, header journal.Header, resume bool) (*journal.Journal, map[string]journal.Status, error) {
+	if resume {
+		if _, err := os.Stat(path); err == nil {
+			j, existing, statuses, err := journal.Open(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := existing.Check(header); err != nil {
+				j.Close()
+				return nil, nil, fmt.Errorf("journal %s %v; remove it or run without --resume", path, err)
+			}
+			fmt.Fprintf(os.Stderr, "Resuming from journal: %s\n", path)
+			return j, statuses, nil
+		}
+		fmt.Fprintf(os.Stderr, "No journal found at %s; starting a new import\n", path)
+	}
+
+	j, err := journal.Create(path, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return j, map[string]journal.Status{}, nil
+}
+
+// connectOpenBao creates an OpenBao client, verifies the connection and
+// logs in. retryer may be nil to use the API client's default retries. The
+// caller must Close the client to stop token renewal.
+func connectOpenBao(ctx context.Context, addr, mount, namespace string, headerStrings []string, tlsSkipVerify bool, auth *authFlags, retryer *retry.Retryer) (*openbao.Client, error) {
+	// Parse custom headers
+	headers, err := openbao.ParseHeaders(headerStrings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	method, err := auth.authMethod()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create OpenBao client
-	fmt.Fprintf(os.Stderr, "Connecting to OpenBao: %s\n", importOpenBaoAddr)
+	if namespace != "" {
+		fmt.Fprintf(os.Stderr, "Connecting to OpenBao: %s (namespace %s)\n", addr, namespace)
+	} else {
+		fmt.Fprintf(os.Stderr, "Connecting to OpenBao: %s\n", addr)
+	}
 	client, err := openbao.NewClient(openbao.Config{
-		Address:       importOpenBaoAddr,
-		Token:         importOpenBaoToken,
-		Mount:         importMount,
+		Address:       addr,
+		Auth:          method,
+		Mount:         mount,
+		Namespace:     namespace,
 		Headers:       headers,
-		TLSSkipVerify: importTLSSkipVerify,
+		TLSSkipVerify: tlsSkipVerify,
+		CACert:        auth.caCert,
+		ClientCert:    auth.clientCert,
+		ClientKey:     auth.clientKey,
 		Timeout:       30 * time.Second,
+		Retryer:       retryer,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create OpenBao client: %w", err)
+		return nil, fmt.Errorf("failed to create OpenBao client: %w", err)
 	}
 
 	// Check connection
 	if err := client.Health(ctx); err != nil {
-		return fmt.Errorf("failed to connect to OpenBao: %w", err)
+		return nil, fmt.Errorf("failed to connect to OpenBao: %w", err)
 	}
-	fmt.Fprintf(os.Stderr, "  Connected successfully\n")
 
-	// Run import
-	if importInteractive {
-		return runInteractiveImport(ctx, client, export, pathPrefix)
+	if err := client.Login(ctx); err != nil {
+		return nil, err
 	}
+	fmt.Fprintf(os.Stderr, "  Connected successfully (%s auth)\n", method.Name())
 
-	return runParallelImport(ctx, client, export, pathPrefix)
+	return client, nil
 }
 
 func normalizePathPrefix(prefix string) string {
@@ -193,31 +573,55 @@ func normalizePathPrefix(prefix string) string {
 	return prefix
 }
 
-func runDryRun(export *schema.ExportFile, pathPrefix string) error {
+// runDryRun prints the secrets an import would write. If policy is set, it
+// also previews how each secret would be merged using client.
+func runDryRun(ctx context.Context, export *schema.ExportFile, rewrites map[string]rewrite.Result, pathPrefix string, namespaces *namespaceRouter, client *openbao.Client, policy merge.Policy) error {
 	fmt.Println("\nDry run - secrets that would be imported:")
 	fmt.Println()
 
 	for _, secret := range export.Secrets {
-		destPath := pathPrefix + secret.Path
+		rewritten := rewrites[secret.Path]
+		namespace, destPath, err := namespaces.destination(rewritten.Path, secret.Metadata.Tags, pathPrefix)
+		if err != nil {
+			return err
+		}
 		keys := getSecretKeys(secret.Data)
-		fmt.Printf("  %s -> %s\n", secret.Path, destPath)
+		fmt.Printf("  %s -> %s\n", secret.Path, destinationLabel(namespace, destPath))
+		printFiredRules(rewritten)
 		fmt.Printf("    Keys: %s\n", strings.Join(keys, ", "))
+		if len(secret.Versions) > 0 {
+			fmt.Printf("    Previous versions: %d\n", len(secret.Versions))
+		}
+		if policy != "" {
+			c := client
+			if namespace != "" {
+				c = namespaces.client(client, namespace)
+			}
+			if err := previewMerge(ctx, c, destPath, secret.Data, policy); err != nil {
+				return err
+			}
+		}
 	}
 
 	fmt.Printf("\nTotal: %d secrets\n", len(export.Secrets))
 	return nil
 }
 
-func runInteractiveImport(ctx context.Context, client *openbao.Client, export *schema.ExportFile, pathPrefix string) error {
+func runInteractiveImport(ctx context.Context, client *openbao.Client, secrets []source.Secret, opts importOptions) error {
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("\nStarting interactive import...")
 	fmt.Println()
 
-	var imported, skipped, failed int
+	var imported, skipped, failed, conflicts int
 	confirmAll := false
 	skipAll := false
 
-	for i, secret := range export.Secrets {
-		destPath := pathPrefix + secret.Path
+	for i, secret := range secrets {
+		destPath := opts.pathPrefix + secret.Path
 
 		// Check if already decided for all
 		if skipAll {
@@ -225,15 +629,30 @@ func runInteractiveImport(ctx context.Context, client *openbao.Client, export *s
 			continue
 		}
 
-		if !confirmAll {
-			// Check if exists
-			exists, err := client.SecretExists(ctx, destPath)
+		// Check if exists, noting the version to write over
+		current, err := lookupSecret(ctx, kv, destPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to check if secret exists: %v\n", err)
+		}
+
+		var merged *merge.Result
+		if opts.mergePolicy != "" {
+			merged, err = mergeWith(secret.Data, current, opts.mergePolicy)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to check if secret exists: %v\n", err)
+				fmt.Fprintf(os.Stderr, "[%d/%d] Error: failed to merge %s: %v\n", i+1, len(secrets), destPath, err)
+				failed++
+				continue
 			}
+			if merged != nil && !merged.Changed() {
+				fmt.Printf("[%d/%d] Secret: %s\n  Unchanged after merge, skipped\n", i+1, len(secrets), secret.Path)
+				skipped++
+				continue
+			}
+		}
 
+		if !confirmAll {
 			// Prompt user
-			confirmation, err := promptImport(i+1, len(export.Secrets), secret, destPath, exists)
+			confirmation, err := promptImport(i+1, len(secrets), secret, destPath, current != nil, merged)
 			if err != nil {
 				return fmt.Errorf("prompt failed: %w", err)
 			}
@@ -258,7 +677,15 @@ func runInteractiveImport(ctx context.Context, client *openbao.Client, export *s
 		}
 
 		// Import the secret
-		if err := client.WriteSecret(ctx, destPath, secret.Data); err != nil {
+		if merged != nil {
+			secret.Data = merged.Data
+		}
+		if err := writeSecret(ctx, kv, destPath, currentVersion(current), secret, opts); err != nil {
+			if errors.Is(err, target.ErrCASMismatch) {
+				fmt.Fprintf(os.Stderr, "  Conflict: %s was written by someone else meanwhile; left unchanged\n", destPath)
+				conflicts++
+				continue
+			}
 			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
 			failed++
 			continue
@@ -270,14 +697,15 @@ func runInteractiveImport(ctx context.Context, client *openbao.Client, export *s
 
 	fmt.Println()
 	fmt.Println("Import complete:")
-	fmt.Printf("  Imported: %d\n", imported)
-	fmt.Printf("  Skipped:  %d\n", skipped)
-	fmt.Printf("  Failed:   %d\n", failed)
+	fmt.Printf("  Imported:  %d\n", imported)
+	fmt.Printf("  Skipped:   %d\n", skipped)
+	fmt.Printf("  Failed:    %d\n", failed)
+	fmt.Printf("  Conflicts: %d\n", conflicts)
 
 	return nil
 }
 
-func promptImport(current, total int, secret source.Secret, destPath string, exists bool) (ImportConfirmation, error) {
+func promptImport(current, total int, secret source.Secret, destPath string, exists bool, merged *merge.Result) (ImportConfirmation, error) {
 	// Display secret info
 	fmt.Printf("[%d/%d] Secret: %s\n", current, total, secret.Path)
 	fmt.Printf("  Destination: %s\n", destPath)
@@ -285,7 +713,9 @@ func promptImport(current, total int, secret source.Secret, destPath string, exi
 	if secret.Metadata.Description != "" {
 		fmt.Printf("  Description: %s\n", secret.Metadata.Description)
 	}
-	if exists {
+	if merged != nil {
+		printMergeResult("  ", merged)
+	} else if exists {
 		fmt.Printf("  ⚠️  Secret already exists at destination\n")
 	}
 
@@ -321,40 +751,34 @@ func promptImport(current, total int, secret source.Secret, destPath string, exi
 	}
 }
 
-func runParallelImport(ctx context.Context, client *openbao.Client, export *schema.ExportFile, pathPrefix string) error {
-	fmt.Fprintf(os.Stderr, "\nImporting secrets with %d workers...\n", importParallelism)
+// runParallelImport imports the secrets received on work using a pool of
+// workers. total is used for progress reporting; pass 0 if it is not known
+// up front.
+func runParallelImport(ctx context.Context, client *openbao.Client, work <-chan source.Secret, total int, opts importOptions) error {
+	fmt.Fprintf(os.Stderr, "\nImporting secrets with %d workers...\n", opts.parallelism)
 
 	var (
-		imported int64
-		skipped  int64
-		failed   int64
-		wg       sync.WaitGroup
+		imported  int64
+		skipped   int64
+		failed    int64
+		conflicts int64
+		wg        sync.WaitGroup
 	)
 
-	// Create work channel
-	work := make(chan source.Secret, len(export.Secrets))
-	results := make(chan ImportResult, len(export.Secrets))
+	results := make(chan ImportResult, opts.parallelism)
 
 	// Start workers
-	for i := 0; i < importParallelism; i++ {
+	for i := 0; i < opts.parallelism; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for secret := range work {
-				result := importSecret(ctx, client, secret, pathPrefix)
+				result := importSecret(ctx, client, secret, opts)
 				results <- result
 			}
 		}()
 	}
 
-	// Send work
-	go func() {
-		for _, secret := range export.Secrets {
-			work <- secret
-		}
-		close(work)
-	}()
-
 	// Collect results
 	go func() {
 		wg.Wait()
@@ -363,55 +787,104 @@ func runParallelImport(ctx context.Context, client *openbao.Client, export *sche
 
 	// Process results
 	for result := range results {
+		if opts.journal != nil {
+			status := journal.StatusImported
+			if result.Skipped {
+				status = journal.StatusSkipped
+			} else if result.Conflict {
+				status = journal.StatusConflict
+			} else if !result.Success {
+				status = journal.StatusFailed
+			}
+			if err := opts.journal.Record(result.Path, status, result.Error); err != nil {
+				fmt.Fprintf(os.Stderr, "\n  Warning: %v\n", err)
+			}
+		}
+
 		if result.Skipped {
 			atomic.AddInt64(&skipped, 1)
 		} else if result.Success {
 			atomic.AddInt64(&imported, 1)
+		} else if result.Conflict {
+			atomic.AddInt64(&conflicts, 1)
+			fmt.Fprintf(os.Stderr, "\n  Conflict importing %s: written by someone else meanwhile; left unchanged\n", result.Path)
 		} else {
 			atomic.AddInt64(&failed, 1)
-			fmt.Fprintf(os.Stderr, "  Error importing %s: %v\n", result.Path, result.Error)
+			fmt.Fprintf(os.Stderr, "\n  Error importing %s: %v\n", result.Path, result.Error)
 		}
 
-		total := atomic.LoadInt64(&imported) + atomic.LoadInt64(&skipped) + atomic.LoadInt64(&failed)
-		fmt.Fprintf(os.Stderr, "\r  Progress: %d/%d", total, len(export.Secrets))
+		done := atomic.LoadInt64(&imported) + atomic.LoadInt64(&skipped) + atomic.LoadInt64(&failed) + atomic.LoadInt64(&conflicts)
+		if total > 0 {
+			fmt.Fprintf(os.Stderr, "\r  Progress: %d/%d", done, total)
+		} else {
+			fmt.Fprintf(os.Stderr, "\r  Progress: %d", done)
+		}
 	}
 
 	fmt.Fprintf(os.Stderr, "\n\n")
 	fmt.Println("Import complete:")
-	fmt.Printf("  Imported: %d\n", imported)
-	fmt.Printf("  Skipped:  %d\n", skipped)
-	fmt.Printf("  Failed:   %d\n", failed)
-
-	if failed > 0 {
-		return fmt.Errorf("%d secrets failed to import", failed)
+	fmt.Printf("  Imported:  %d\n", imported)
+	fmt.Printf("  Skipped:   %d\n", skipped)
+	fmt.Printf("  Failed:    %d\n", failed)
+	fmt.Printf("  Conflicts: %d\n", conflicts)
+	printRetryStats(os.Stdout, "OpenBao", client.RetryStats())
+
+	if failed > 0 || conflicts > 0 {
+		return fmt.Errorf("%d secrets failed to import (%d conflicts)", failed+conflicts, conflicts)
 	}
 
 	return nil
 }
 
-func importSecret(ctx context.Context, client *openbao.Client, secret source.Secret, pathPrefix string) ImportResult {
-	destPath := pathPrefix + secret.Path
+func importSecret(ctx context.Context, client *openbao.Client, secret source.Secret, opts importOptions) ImportResult {
+	namespace, destPath, err := opts.namespaces.destination(secret.Path, secret.Metadata.Tags, opts.pathPrefix)
+	if err != nil {
+		return ImportResult{Path: secret.Path, Error: err}
+	}
+	if namespace != "" {
+		client = opts.namespaces.client(client, namespace)
+	}
 
 	result := ImportResult{
-		Path: destPath,
+		Path: namespacedPath(namespace, destPath),
+	}
+
+	kv, err := client.Target(ctx)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	current, err := lookupSecret(ctx, kv, destPath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to check existence: %w", err)
+		return result
+	}
+	if current != nil && opts.skipExisting && !opts.overwriteAll && !opts.retried[result.Path] {
+		result.Skipped = true
+		result.Success = true
+		return result
 	}
 
-	// Check if exists when skip-existing is enabled
-	if importSkipExisting && !importOverwriteAll {
-		exists, err := client.SecretExists(ctx, destPath)
+	if opts.mergePolicy != "" {
+		merged, err := mergeWith(secret.Data, current, opts.mergePolicy)
 		if err != nil {
-			result.Error = fmt.Errorf("failed to check existence: %w", err)
+			result.Error = fmt.Errorf("failed to merge %s: %w", destPath, err)
 			return result
 		}
-		if exists {
+		if merged != nil && !merged.Changed() {
 			result.Skipped = true
 			result.Success = true
 			return result
 		}
+		if merged != nil {
+			secret.Data = merged.Data
+		}
 	}
 
 	// Write the secret
-	if err := client.WriteSecret(ctx, destPath, secret.Data); err != nil {
+	if err := writeSecret(ctx, kv, destPath, currentVersion(current), secret, opts); err != nil {
+		result.Conflict = errors.Is(err, target.ErrCASMismatch)
 		result.Error = err
 		return result
 	}
@@ -420,6 +893,169 @@ func importSecret(ctx context.Context, client *openbao.Client, secret source.Sec
 	return result
 }
 
+// lookupSecret returns the current version of the secret at destPath, or nil
+// if it does not exist. Secrets on targets without versions are returned as
+// version 0.
+func lookupSecret(ctx context.Context, kv target.Target, destPath string) (*target.Version, error) {
+	if versioned, ok := kv.(target.VersionedTarget); ok {
+		return versioned.ReadVersion(ctx, destPath)
+	}
+
+	data, err := kv.Read(ctx, destPath)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return &target.Version{Data: data}, nil
+}
+
+// currentVersion returns the version to write over for Check-And-Set: 0 if
+// the secret does not exist.
+func currentVersion(current *target.Version) int {
+	if current == nil {
+		return 0
+	}
+	return current.Version
+}
+
+// writeSecret writes a secret and, if enabled, its previous versions and
+// source metadata. On a versioned target every write uses Check-And-Set
+// starting from version, the current version seen when deciding to write, so
+// a secret written concurrently fails with target.ErrCASMismatch instead of
+// being overwritten.
+//
+// Previous versions are only replayed into a secret without a version
+// history, so reruns do not stack the history again. If the replay fails,
+// the current version is still written so the secret never keeps serving a
+// previous value, and the replay error is returned.
+func writeSecret(ctx context.Context, kv target.Target, destPath string, version int, secret source.Secret, opts importOptions) error {
+	casTarget, cas := kv.(target.VersionedTarget)
+	write := func(data map[string]interface{}) error {
+		if !cas {
+			return kv.Write(ctx, destPath, data)
+		}
+		if err := casTarget.WriteCAS(ctx, destPath, data, version); err != nil {
+			return err
+		}
+		version++
+		return nil
+	}
+
+	var replayErr error
+	if opts.replayVersions && len(secret.Versions) > 0 && version == 0 {
+		versioned, err := versionedTarget(kv, "--replay-versions")
+		if err != nil {
+			return err
+		}
+		maxVersions, err := versioned.MaxVersions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, previous := range replayedVersions(secret.Versions, maxVersions) {
+			if err := write(previous.Data); err != nil {
+				replayErr = fmt.Errorf("failed to replay version %s: %w", previous.VersionID, err)
+				break
+			}
+		}
+	}
+
+	if err := write(secret.Data); err != nil {
+		if replayErr != nil {
+			return fmt.Errorf("%v; then failed to write the current version: %w", replayErr, err)
+		}
+		return err
+	}
+
+	if opts.writeMetadata {
+		versioned, err := versionedTarget(kv, "--write-metadata")
+		if err != nil {
+			return err
+		}
+		custom := openbao.CustomMetadata(opts.sourceName, secret.Metadata, opts.metadataPrefix)
+		if err := versioned.WriteCustomMetadata(ctx, destPath, custom); err != nil {
+			return err
+		}
+	}
+
+	return replayErr
+}
+
+// versionedTarget returns kv as a target.VersionedTarget, or an error naming
+// the feature that needs one.
+func versionedTarget(kv target.Target, feature string) (target.VersionedTarget, error) {
+	versioned, ok := kv.(target.VersionedTarget)
+	if !ok {
+		return nil, fmt.Errorf("%s requires a KV v2 mount (target is %s)", feature, kv.Name())
+	}
+	return versioned, nil
+}
+
+// checkTarget detects the KV version of the client's mount and fails early
+// if an enabled option needs a KV v2 mount. With namespace routing the mount
+// may only exist in the routed namespaces, so it is then detected per
+// namespace on first use unless an option needs it now.
+func checkTarget(ctx context.Context, client *openbao.Client, opts importOptions) error {
+	if opts.namespaces != nil && !opts.replayVersions && !opts.writeMetadata {
+		return nil
+	}
+
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "  Mount %s: %s\n", client.Mount(), kv.Name())
+
+	if opts.replayVersions {
+		if _, err := versionedTarget(kv, "--replay-versions"); err != nil {
+			return err
+		}
+	}
+	if opts.writeMetadata {
+		if _, err := versionedTarget(kv, "--write-metadata"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayedVersions returns the newest previous versions that fit in the
+// version history alongside the current one.
+func replayedVersions(versions []source.SecretVersion, maxVersions int) []source.SecretVersion {
+	if keep := maxVersions - 1; len(versions) > keep {
+		return versions[len(versions)-keep:]
+	}
+	return versions
+}
+
+// checkVersionHistory reports the mount's max_versions and warns about
+// secrets with more previous versions than it keeps.
+func checkVersionHistory(ctx context.Context, client *openbao.Client, secrets []source.Secret) error {
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+	versioned, err := versionedTarget(kv, "--replay-versions")
+	if err != nil {
+		return err
+	}
+	maxVersions, err := versioned.MaxVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	trimmed := 0
+	for _, secret := range secrets {
+		if len(secret.Versions) >= maxVersions {
+			trimmed++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "  Replaying version history (mount keeps %d versions)\n", maxVersions)
+	if trimmed > 0 {
+		fmt.Fprintf(os.Stderr, "  Warning: %d secrets have more versions than max_versions; their oldest versions will not be replayed\n", trimmed)
+	}
+	return nil
+}
+
 func getSecretKeys(data map[string]interface{}) []string {
 	keys := make([]string, 0, len(data))
 	for k := range data {
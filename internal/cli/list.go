@@ -34,6 +34,7 @@ var (
 	listIncludes []string
 	listExcludes []string
 	listRegion   string
+	listOpts     []string
 )
 
 func init() {
@@ -41,6 +42,7 @@ func init() {
 	listCmd.Flags().StringArrayVarP(&listIncludes, "include", "i", []string{}, "Include patterns (glob syntax, can be specified multiple times)")
 	listCmd.Flags().StringArrayVarP(&listExcludes, "exclude", "e", []string{}, "Exclude patterns (glob syntax, can be specified multiple times)")
 	listCmd.Flags().StringVar(&listRegion, "region", "", "AWS region (for aws-secrets-manager source)")
+	listCmd.Flags().StringArrayVar(&listOpts, "source-opt", []string{}, "Source-specific option (can be specified multiple times, format: 'key=value')")
 
 	listCmd.MarkFlagRequired("source")
 
@@ -50,20 +52,9 @@ func init() {
 func runList(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Get the source
-	src, err := source.Get(listSource)
+	src, err := configureSource(ctx, listSource, listRegion, "", listOpts)
 	if err != nil {
-		return fmt.Errorf("failed to get source: %w", err)
-	}
-
-	// Configure the source
-	opts := make(map[string]interface{})
-	if listRegion != "" {
-		opts["region"] = listRegion
-	}
-
-	if err := src.Configure(ctx, opts); err != nil {
-		return fmt.Errorf("failed to configure source: %w", err)
+		return err
 	}
 
 	// Combine include and exclude patterns for filtering
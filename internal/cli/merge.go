@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/merge"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// mergeFlags holds the merge conflict strategy flags shared by the commands
+// that write secrets.
+type mergeFlags struct {
+	enabled bool
+	policy  string
+}
+
+// register adds the merge flags to cmd.
+func (f *mergeFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.enabled, "merge", false, "Merge the keys of existing secrets with the source keys instead of skipping or overwriting them")
+	cmd.Flags().StringVar(&f.policy, "merge-policy", string(merge.PolicySource), "Value of keys that differ when merging: source, destination or fail")
+}
+
+// load returns the merge policy, or an empty policy if --merge is not set.
+func (f *mergeFlags) load() (merge.Policy, error) {
+	policy, err := merge.ParsePolicy(f.policy)
+	if err != nil || !f.enabled {
+		return "", err
+	}
+	return policy, nil
+}
+
+// mergeWith merges data into the current data of its destination. It
+// returns nil if there is nothing to merge with because the secret does not
+// exist or its current version is deleted.
+func mergeWith(data map[string]interface{}, current *target.Version, policy merge.Policy) (*merge.Result, error) {
+	if current == nil || current.Data == nil {
+		return nil, nil
+	}
+	return merge.Merge(data, current.Data, policy)
+}
+
+// printMergeResult prints the per-key outcome of a merge without values.
+func printMergeResult(indent string, result *merge.Result) {
+	if !result.Changed() {
+		fmt.Printf("%sMerge: no changes\n", indent)
+		return
+	}
+
+	fmt.Printf("%sMerge:\n", indent)
+	for _, k := range result.Added {
+		fmt.Printf("%s  + %s\n", indent, k)
+	}
+	for _, k := range result.Overwritten {
+		fmt.Printf("%s  ~ %s (source value)\n", indent, k)
+	}
+	for _, k := range result.Kept {
+		fmt.Printf("%s  = %s (destination value)\n", indent, k)
+	}
+	for _, k := range result.Preserved {
+		fmt.Printf("%s  = %s (destination only)\n", indent, k)
+	}
+}
+
+// previewMerge prints how data would be merged into the secret at destPath
+// for a dry run.
+func previewMerge(ctx context.Context, client *openbao.Client, destPath string, data map[string]interface{}, policy merge.Policy) error {
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := lookupSecret(ctx, kv, destPath)
+	if err != nil {
+		return err
+	}
+
+	result, err := mergeWith(data, current, policy)
+	switch {
+	case err != nil:
+		fmt.Printf("    Merge: fails: %v\n", err)
+	case result == nil:
+		fmt.Printf("    Merge: new secret\n")
+	default:
+		printMergeResult("    ", result)
+	}
+	return nil
+}
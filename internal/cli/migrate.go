@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/rewrite"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate secrets from a source directly to OpenBao",
+	Long: `Migrate secrets from a source directly into an OpenBao KV v1 or v2 secrets engine.
+
+Secrets are streamed from the source to OpenBao in memory, so no intermediate
+export file is ever written to disk. Filters, path prefix and conflict
+resolution flags behave exactly as they do for export and import.
+
+Examples:
+  # Migrate all secrets from AWS Secrets Manager
+  openbao-secrets-importer migrate \
+    --source aws-secrets-manager \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --mount secret
+
+  # Migrate a subset under a prefix, overwriting existing secrets
+  openbao-secrets-importer migrate \
+    --source aws-secrets-manager \
+    --include "prod/**" --exclude "**/temp/*" \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --path-prefix "aws-imported/" \
+    --overwrite-all
+
+  # Dry run to preview without fetching values or writing
+  openbao-secrets-importer migrate \
+    --source aws-secrets-manager \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --dry-run`,
+	RunE: runMigrate,
+}
+
+var (
+	migrateSource          string
+	migrateIncludes        []string
+	migrateExcludes        []string
+	migrateRegion          string
+	migrateDefaultKey      string
+	migrateOpts            []string
+	migrateOpenBaoAddr     string
+	migrateAuth            authFlags
+	migrateMount           string
+	migrateNamespace       string
+	migrateNamespaceFrom   string
+	migrateHeaders         []string
+	migratePathPrefix      string
+	migrateSkipExisting    bool
+	migrateOverwriteAll    bool
+	migrateInteractive     bool
+	migrateParallelism     int
+	migrateDryRun          bool
+	migrateTLSSkipVerify   bool
+	migrateWriteMetadata   bool
+	migrateMetadataPrefix  string
+	migrateIncludeVersions bool
+	migrateRewrite         rewriteFlags
+	migrateTransform       transformFlags
+	migrateRetry           retryFlags
+	migrateMountFlags      mountFlags
+	migrateMerge           mergeFlags
+)
+
+func init() {
+	migrateCmd.Flags().StringVarP(&migrateSource, "source", "s", "", "Secret source (e.g., aws-secrets-manager)")
+	migrateCmd.Flags().StringArrayVarP(&migrateIncludes, "include", "i", []string{}, "Include patterns (glob syntax, can be specified multiple times)")
+	migrateCmd.Flags().StringArrayVarP(&migrateExcludes, "exclude", "e", []string{}, "Exclude patterns (glob syntax, can be specified multiple times)")
+	migrateCmd.Flags().StringVar(&migrateRegion, "region", "", "AWS region (for aws-secrets-manager source)")
+	migrateCmd.Flags().StringVar(&migrateDefaultKey, "default-key", "value", "Key name for non-JSON secrets (plain text, binary)")
+	migrateCmd.Flags().StringArrayVar(&migrateOpts, "source-opt", []string{}, "Source-specific option (can be specified multiple times, format: 'key=value')")
+	migrateCmd.Flags().StringVar(&migrateOpenBaoAddr, "openbao-addr", "", "OpenBao server address (e.g., https://openbao:8200)")
+	migrateAuth.register(migrateCmd)
+	migrateCmd.Flags().StringVar(&migrateMount, "mount", "secret", "KV mount path (v1 or v2, detected automatically)")
+	migrateCmd.Flags().StringVar(&migrateNamespace, "namespace", "", "OpenBao namespace to log in to and migrate into")
+	migrateCmd.Flags().StringVar(&migrateNamespaceFrom, "namespace-from", "", "Migrate each secret into a child namespace taken from its path ('path': first segment) or a tag ('tag:<key>')")
+	migrateCmd.Flags().StringArrayVar(&migrateHeaders, "header", []string{}, "Custom HTTP header (can be specified multiple times, format: 'Key: Value')")
+	migrateCmd.Flags().StringVar(&migratePathPrefix, "path-prefix", "", "Prefix to prepend to all secret paths")
+	migrateCmd.Flags().BoolVar(&migrateSkipExisting, "skip-existing", true, "Skip secrets that already exist")
+	migrateCmd.Flags().BoolVar(&migrateOverwriteAll, "overwrite-all", false, "Overwrite all existing secrets without prompting")
+	migrateMerge.register(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrateInteractive, "interactive", false, "Prompt for each secret")
+	migrateCmd.Flags().IntVar(&migrateParallelism, "parallelism", 5, "Number of parallel import workers")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Preview migration without fetching values or writing to OpenBao")
+	migrateCmd.Flags().BoolVar(&migrateTLSSkipVerify, "tls-skip-verify", false, "Skip TLS certificate verification")
+	migrateCmd.Flags().BoolVar(&migrateWriteMetadata, "write-metadata", false, "Write source metadata (ID, description, tags, timestamps) to KV v2 custom_metadata")
+	migrateCmd.Flags().StringVar(&migrateMetadataPrefix, "metadata-prefix", openbao.DefaultMetadataPrefix, "Prefix for custom_metadata keys written by --write-metadata")
+	migrateRewrite.register(migrateCmd)
+	migrateTransform.register(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrateIncludeVersions, "include-versions", false, "Migrate the previous versions of each secret into the KV v2 version history, oldest first")
+	migrateRetry.register(migrateCmd, true, true)
+	migrateMountFlags.register(migrateCmd)
+
+	migrateCmd.MarkFlagRequired("source")
+	migrateCmd.MarkFlagRequired("openbao-addr")
+
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Validate flags
+	if migrateParallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1")
+	}
+	if migrateOverwriteAll && migrateInteractive {
+		return fmt.Errorf("--overwrite-all and --interactive cannot be used together")
+	}
+
+	mergePolicy, err := migrateMerge.load()
+	if err != nil {
+		return err
+	}
+	if mergePolicy != "" && migrateOverwriteAll {
+		return fmt.Errorf("--merge and --overwrite-all cannot be used together")
+	}
+	if mergePolicy != "" && migrateIncludeVersions {
+		return fmt.Errorf("--merge and --include-versions cannot be used together")
+	}
+
+	if migrateOverwriteAll || mergePolicy != "" {
+		migrateSkipExisting = false
+	}
+
+	namespaces, err := newNamespaceRouter(migrateNamespaceFrom)
+	if err != nil {
+		return err
+	}
+	if namespaces != nil && migrateInteractive {
+		return fmt.Errorf("--namespace-from and --interactive cannot be used together")
+	}
+
+	mountConfig, err := migrateMountFlags.config(cmd, migrateDryRun, migrateNamespaceFrom)
+	if err != nil {
+		return err
+	}
+
+	// Validate filter patterns before touching the source
+	if _, err := filter.NewPathFilter(migrateIncludes, migrateExcludes); err != nil {
+		return fmt.Errorf("invalid filter pattern: %w", err)
+	}
+
+	rules, err := migrateRewrite.load()
+	if err != nil {
+		return err
+	}
+	transforms, err := migrateTransform.load()
+	if err != nil {
+		return err
+	}
+
+	sourceRetryer, err := migrateRetry.sourceRetryer()
+	if err != nil {
+		return err
+	}
+	openbaoRetryer, err := migrateRetry.openbaoRetryer()
+	if err != nil {
+		return err
+	}
+
+	src, err := configureSource(ctx, migrateSource, migrateRegion, migrateDefaultKey, migrateOpts)
+	if err != nil {
+		return err
+	}
+	if migrateIncludeVersions {
+		src, err = source.WithVersions(src)
+		if err != nil {
+			return err
+		}
+	}
+	src = source.WithRetry(src, sourceRetryer)
+
+	patterns := filter.CombinePatterns(migrateIncludes, migrateExcludes)
+	if len(migrateIncludes) == 0 {
+		patterns = append(patterns, "**")
+	}
+
+	pathPrefix := normalizePathPrefix(migratePathPrefix)
+
+	if migrateDryRun {
+		return runMigrateDryRun(ctx, src, patterns, rules, pathPrefix, namespaces)
+	}
+
+	// Check for collisions before writing anything, then stream the
+	// secrets of that listing rather than listing the source again
+	if len(rules) > 0 {
+		infos, err := src.List(ctx, patterns)
+		if err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+		if _, err := rules.RewriteAll(secretInfoPaths(infos)); err != nil {
+			return err
+		}
+		src = source.WithListing(src, infos)
+	}
+	rewriter := newStreamRewriter(rules)
+
+	// prepare transforms a secret's data, then rewrites its path
+	prepare := func(secret *source.Secret) error {
+		if err := transforms.Apply(secret); err != nil {
+			return err
+		}
+		return rewriter.rewrite(secret)
+	}
+
+	client, err := connectOpenBao(ctx, migrateOpenBaoAddr, migrateMount, migrateNamespace, migrateHeaders, migrateTLSSkipVerify, &migrateAuth, openbaoRetryer)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if migrateMountFlags.ensure {
+		if err := ensureMount(ctx, client, mountConfig); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Streaming secrets from %s...\n", src.Name())
+
+	opts := importOptions{
+		pathPrefix:     pathPrefix,
+		skipExisting:   migrateSkipExisting,
+		overwriteAll:   migrateOverwriteAll,
+		parallelism:    migrateParallelism,
+		namespaces:     namespaces,
+		writeMetadata:  migrateWriteMetadata,
+		metadataPrefix: migrateMetadataPrefix,
+		sourceName:     src.Name(),
+		replayVersions: migrateIncludeVersions,
+		mergePolicy:    mergePolicy,
+	}
+
+	if err := checkTarget(ctx, client, opts); err != nil {
+		return err
+	}
+	if opts.replayVersions {
+		if err := checkVersionHistory(ctx, client, nil); err != nil {
+			return err
+		}
+	}
+
+	secretChan, errChan := src.Export(ctx, patterns)
+
+	if migrateInteractive {
+		// Prompts are sequential, so fetch everything into memory first
+		var secrets []source.Secret
+		var sourceErrors int
+		drainExport(secretChan, errChan,
+			func(secret *source.Secret) {
+				if err := prepare(secret); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
+					sourceErrors++
+					return
+				}
+				secrets = append(secrets, *secret)
+			},
+			func(err error) {
+				fmt.Fprintf(os.Stderr, "  Warning: %v\n", err)
+				sourceErrors++
+			},
+		)
+		sort.Slice(secrets, func(i, j int) bool {
+			return secrets[i].Path < secrets[j].Path
+		})
+
+		if err := runInteractiveImport(ctx, client, secrets, opts); err != nil {
+			return err
+		}
+		if sourceErrors > 0 {
+			return fmt.Errorf("%d secrets failed to read from source", sourceErrors)
+		}
+		return nil
+	}
+
+	// Forward secrets from the source straight into the import workers
+	work := make(chan source.Secret)
+	forwarded := make(chan struct{})
+	var sourceErrors int
+	go func() {
+		defer close(forwarded)
+		defer close(work)
+		drainExport(secretChan, errChan,
+			func(secret *source.Secret) {
+				if err := prepare(secret); err != nil {
+					fmt.Fprintf(os.Stderr, "\n  Error: %v\n", err)
+					sourceErrors++
+					return
+				}
+				select {
+				case work <- *secret:
+				case <-ctx.Done():
+				}
+			},
+			func(err error) {
+				fmt.Fprintf(os.Stderr, "\n  Error reading from source: %v\n", err)
+				sourceErrors++
+			},
+		)
+	}()
+
+	importErr := runParallelImport(ctx, client, work, 0, opts)
+
+	// Stop the forwarder in case the import ended before work was closed;
+	// once it has finished, sourceErrors is final
+	cancel()
+	<-forwarded
+
+	printRetryStats(os.Stdout, "Source", sourceRetryer.Stats())
+
+	if sourceErrors > 0 {
+		fmt.Printf("  Source errors: %d\n", sourceErrors)
+		if importErr == nil {
+			return fmt.Errorf("%d secrets failed to read from source", sourceErrors)
+		}
+	}
+
+	return importErr
+}
+
+func runMigrateDryRun(ctx context.Context, src source.Source, patterns []string, rules rewrite.Rules, pathPrefix string, namespaces *namespaceRouter) error {
+	infos, err := src.List(ctx, patterns)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	rewrites, err := rules.RewriteAll(secretInfoPaths(infos))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nDry run - secrets that would be migrated:")
+	fmt.Println()
+
+	for _, info := range infos {
+		rewritten := rewrites[info.Path]
+		namespace, destPath, err := namespaces.destination(rewritten.Path, info.Tags, pathPrefix)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s -> %s\n", info.Path, destinationLabel(namespace, destPath))
+		printFiredRules(rewritten)
+	}
+
+	fmt.Printf("\nTotal: %d secrets\n", len(infos))
+	return nil
+}
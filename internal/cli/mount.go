@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// mountFlags holds the mount provisioning flags shared by the commands that
+// write to OpenBao.
+type mountFlags struct {
+	ensure             bool
+	maxVersions        int
+	casRequired        bool
+	deleteVersionAfter time.Duration
+}
+
+// register adds the mount provisioning flags to cmd.
+func (f *mountFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.ensure, "ensure-mount", false, "Enable a KV v2 secrets engine at --mount if there is none and apply the mount settings below")
+	cmd.Flags().IntVar(&f.maxVersions, "max-versions", 0, "With --ensure-mount, set the mount's max_versions (0: the server default)")
+	cmd.Flags().BoolVar(&f.casRequired, "cas-required", false, "With --ensure-mount, set the mount's cas_required")
+	cmd.Flags().DurationVar(&f.deleteVersionAfter, "delete-version-after", 0, "With --ensure-mount, set the mount's delete_version_after (0: never)")
+}
+
+// config validates the flags and returns the mount settings given on cmd.
+// Settings that were not given are left unchanged on the mount.
+func (f *mountFlags) config(cmd *cobra.Command, dryRun bool, namespaceFrom string) (openbao.MountConfig, error) {
+	var cfg openbao.MountConfig
+	flags := cmd.Flags()
+
+	if !f.ensure {
+		for _, name := range []string{"max-versions", "cas-required", "delete-version-after"} {
+			if flags.Changed(name) {
+				return cfg, fmt.Errorf("--%s requires --ensure-mount", name)
+			}
+		}
+		return cfg, nil
+	}
+
+	if dryRun {
+		return cfg, fmt.Errorf("--ensure-mount cannot be used with --dry-run")
+	}
+	if namespaceFrom != "" {
+		return cfg, fmt.Errorf("--ensure-mount cannot be used with --namespace-from")
+	}
+
+	if flags.Changed("max-versions") {
+		if f.maxVersions < 0 {
+			return cfg, fmt.Errorf("--max-versions must not be negative")
+		}
+		cfg.MaxVersions = &f.maxVersions
+	}
+	if flags.Changed("cas-required") {
+		cfg.CASRequired = &f.casRequired
+	}
+	if flags.Changed("delete-version-after") {
+		if f.deleteVersionAfter < 0 {
+			return cfg, fmt.Errorf("--delete-version-after must not be negative")
+		}
+		cfg.DeleteVersionAfter = &f.deleteVersionAfter
+	}
+
+	return cfg, nil
+}
+
+// ensureMount provisions the client's mount and reports what it changed.
+func ensureMount(ctx context.Context, client *openbao.Client, cfg openbao.MountConfig) error {
+	changes, err := client.EnsureMount(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(os.Stderr, "  Mount %s/ is up to date\n", client.Mount())
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  Mount: %s\n", change)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// namespaceRouter derives the destination namespace of every secret in a
+// multi-namespace import, either from the first segment of its path or from
+// one of its tags. Namespaces are children of the client's namespace.
+type namespaceRouter struct {
+	tag string // Tag holding the namespace; empty to use the first path segment
+
+	mu      sync.Mutex
+	clients map[string]*openbao.Client
+}
+
+// newNamespaceRouter parses a --namespace-from value: "path" or "tag:<key>".
+// It returns nil for an empty value.
+func newNamespaceRouter(from string) (*namespaceRouter, error) {
+	switch {
+	case from == "":
+		return nil, nil
+	case from == "path":
+		return &namespaceRouter{clients: make(map[string]*openbao.Client)}, nil
+	case strings.HasPrefix(from, "tag:") && len(from) > len("tag:"):
+		return &namespaceRouter{tag: strings.TrimPrefix(from, "tag:"), clients: make(map[string]*openbao.Client)}, nil
+	default:
+		return nil, fmt.Errorf("invalid --namespace-from: %s (expected 'path' or 'tag:<key>')", from)
+	}
+}
+
+// client returns the client for a child namespace of base, creating it on
+// first use.
+func (r *namespaceRouter) client(base *openbao.Client, namespace string) *openbao.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.clients[namespace]
+	if !ok {
+		c = base.WithNamespace(namespace)
+		r.clients[namespace] = c
+	}
+	return c
+}
+
+// destination returns the namespace and destination path of a secret. The
+// namespace is empty if r is nil. In path mode the first path segment is
+// the namespace and is removed from the destination path.
+func (r *namespaceRouter) destination(path string, tags map[string]string, pathPrefix string) (namespace, destPath string, err error) {
+	if r == nil {
+		return "", pathPrefix + path, nil
+	}
+
+	if r.tag != "" {
+		namespace = strings.Trim(tags[r.tag], "/")
+		if namespace == "" {
+			return "", "", fmt.Errorf("secret %s has no %q tag to take its namespace from", path, r.tag)
+		}
+		return namespace, pathPrefix + path, nil
+	}
+
+	namespace, rest, ok := strings.Cut(path, "/")
+	if !ok || namespace == "" || rest == "" {
+		return "", "", fmt.Errorf("secret %s has no namespace segment in its path", path)
+	}
+	return namespace, pathPrefix + rest, nil
+}
+
+// namespacedPath qualifies a destination path with its namespace, as shown in
+// progress output and recorded in the journal.
+func namespacedPath(namespace, path string) string {
+	if namespace == "" {
+		return path
+	}
+	return namespace + "/" + path
+}
+
+// destinationLabel describes a destination path and its namespace for
+// dry-run output.
+func destinationLabel(namespace, path string) string {
+	if namespace == "" {
+		return path
+	}
+	return fmt.Sprintf("%s (namespace %s)", path, namespace)
+}
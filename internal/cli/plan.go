@@ -0,0 +1,483 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/plan"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/schema"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+var importPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Compute the changes an import would make and write them to a plan file",
+	Long: `Compare an export file with the current contents of OpenBao and write a
+reviewable plan. Every secret is classified as create, update, unchanged or
+skip; updates list the added, removed and changed keys. Secret values are
+never printed or written to the plan.
+
+The plan records the digest of the export file and the current version of
+every destination secret, so "import apply" can execute exactly this plan and
+refuse if anything changed in between. Transforms are recorded as well and
+applied again by "import apply"; a transforms file is pinned by its digest.
+
+With --prune, every secret under --path-prefix that is not in the export file
+and not matched by a --prune-protect glob is planned for deletion, using
+--prune-mode delete (soft delete) or destroy.
+
+Examples:
+  openbao-secrets-importer import plan \
+    --input secrets.json \
+    --output plan.json \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --overwrite-all`,
+	RunE: runImportPlan,
+}
+
+var importApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a plan file written by import plan",
+	Long: `Apply a plan written by "import plan". The secret values are read again from
+the export file referenced by the plan, which must not have changed since
+planning. Before writing anything, the current version of every destination
+secret is compared with the version recorded in the plan; if any secret
+drifted the apply is refused. Writes use Check-And-Set with the planned
+version, so changes made during the apply are detected as well. Planned
+deletions from --prune are carried out last, and only if every write
+succeeded.
+
+Examples:
+  openbao-secrets-importer import apply \
+    --plan plan.json \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx`,
+	RunE: runImportApply,
+}
+
+var (
+	planInput         string
+	planOutput        string
+	planOpenBaoAddr   string
+	planAuth          authFlags
+	planMount         string
+	planNamespace     string
+	planHeaders       []string
+	planPathPrefix    string
+	planSkipExisting  bool
+	planOverwriteAll  bool
+	planTLSSkipVerify bool
+	planIdentities    []string
+	planRewrite       rewriteFlags
+	planTransform     transformFlags
+	planPrune         pruneFlags
+	planRetry         retryFlags
+
+	applyPlan          string
+	applyInput         string
+	applyOpenBaoAddr   string
+	applyAuth          authFlags
+	applyHeaders       []string
+	applyTLSSkipVerify bool
+	applyIdentities    []string
+	applyRetry         retryFlags
+)
+
+func init() {
+	importPlanCmd.Flags().StringVarP(&planInput, "input", "f", "", "Input file path")
+	importPlanCmd.Flags().StringVarP(&planOutput, "output", "o", "", "Plan file path")
+	importPlanCmd.Flags().StringVar(&planOpenBaoAddr, "openbao-addr", "", "OpenBao server address (e.g., https://openbao:8200)")
+	planAuth.register(importPlanCmd)
+	importPlanCmd.Flags().StringVar(&planMount, "mount", "secret", "KV v2 mount path")
+	importPlanCmd.Flags().StringVar(&planNamespace, "namespace", "", "OpenBao namespace to log in to and import into")
+	importPlanCmd.Flags().StringArrayVar(&planHeaders, "header", []string{}, "Custom HTTP header (can be specified multiple times, format: 'Key: Value')")
+	importPlanCmd.Flags().StringVar(&planPathPrefix, "path-prefix", "", "Prefix to prepend to all secret paths")
+	importPlanCmd.Flags().BoolVar(&planSkipExisting, "skip-existing", true, "Skip secrets that already exist")
+	importPlanCmd.Flags().BoolVar(&planOverwriteAll, "overwrite-all", false, "Overwrite all existing secrets")
+	importPlanCmd.Flags().BoolVar(&planTLSSkipVerify, "tls-skip-verify", false, "Skip TLS certificate verification")
+	importPlanCmd.Flags().StringArrayVar(&planIdentities, "identity", []string{}, "age identity file for encrypted export files (can be specified multiple times)")
+	planRewrite.register(importPlanCmd)
+	planTransform.register(importPlanCmd)
+	planPrune.register(importPlanCmd)
+	planRetry.register(importPlanCmd, true, false)
+
+	importPlanCmd.MarkFlagRequired("input")
+	importPlanCmd.MarkFlagRequired("output")
+	importPlanCmd.MarkFlagRequired("openbao-addr")
+
+	importApplyCmd.Flags().StringVar(&applyPlan, "plan", "", "Plan file path")
+	importApplyCmd.Flags().StringVarP(&applyInput, "input", "f", "", "Export file path (defaults to the file recorded in the plan)")
+	importApplyCmd.Flags().StringVar(&applyOpenBaoAddr, "openbao-addr", "", "OpenBao server address (e.g., https://openbao:8200)")
+	applyAuth.register(importApplyCmd)
+	importApplyCmd.Flags().StringArrayVar(&applyHeaders, "header", []string{}, "Custom HTTP header (can be specified multiple times, format: 'Key: Value')")
+	importApplyCmd.Flags().BoolVar(&applyTLSSkipVerify, "tls-skip-verify", false, "Skip TLS certificate verification")
+	importApplyCmd.Flags().StringArrayVar(&applyIdentities, "identity", []string{}, "age identity file for encrypted export files (can be specified multiple times)")
+	applyRetry.register(importApplyCmd, true, false)
+
+	importApplyCmd.MarkFlagRequired("plan")
+	importApplyCmd.MarkFlagRequired("openbao-addr")
+
+	importCmd.AddCommand(importPlanCmd)
+	importCmd.AddCommand(importApplyCmd)
+}
+
+func runImportPlan(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if planOverwriteAll {
+		planSkipExisting = false
+	}
+
+	rules, err := planRewrite.load()
+	if err != nil {
+		return err
+	}
+	transforms, err := planTransform.load()
+	if err != nil {
+		return err
+	}
+	prune, err := planPrune.load(cmd, normalizePathPrefix(planPathPrefix))
+	if err != nil {
+		return err
+	}
+
+	digest, err := plan.FileDigest(planInput)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Reading export file: %s\n", planInput)
+	export, err := schema.ValidateFileWith(planInput, exportDecrypter(planIdentities))
+	if err != nil {
+		return fmt.Errorf("failed to read/validate export file: %w", err)
+	}
+
+	if err := transformSecrets(transforms, export.Secrets); err != nil {
+		return err
+	}
+
+	rewrites, err := rules.RewriteAll(secretPaths(export.Secrets))
+	if err != nil {
+		return err
+	}
+
+	retryer, err := planRetry.openbaoRetryer()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectOpenBao(ctx, planOpenBaoAddr, planMount, planNamespace, planHeaders, planTLSSkipVerify, &planAuth, retryer)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	kv, err := planTarget(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	p := &plan.Plan{
+		Version:     plan.Version,
+		CreatedAt:   time.Now().UTC(),
+		Input:       planInput,
+		InputDigest: digest,
+		Address:     client.Address(),
+		Mount:       client.Mount(),
+		Namespace:   client.Namespace(),
+		PathPrefix:  normalizePathPrefix(planPathPrefix),
+		Transforms:  planTransform.specs,
+	}
+
+	// Apply reads the values again, so it must transform them the same way
+	if planTransform.file != "" {
+		p.TransformFile = planTransform.file
+		if p.TransformFileDigest, err = plan.FileDigest(planTransform.file); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Reading current state of %d secrets...\n", len(export.Secrets))
+	for i, secret := range export.Secrets {
+		change, err := planSecret(ctx, kv, secret, p.PathPrefix+rewrites[secret.Path].Path, planSkipExisting)
+		if err != nil {
+			return err
+		}
+		p.Changes = append(p.Changes, change)
+		fmt.Fprintf(os.Stderr, "\r  Progress: %d/%d", i+1, len(export.Secrets))
+	}
+	fmt.Fprintf(os.Stderr, "\n\n")
+
+	if prune != nil {
+		deletes, err := prune.candidates(ctx, client, p.PathPrefix, keepPaths(export.Secrets, rewrites, p.PathPrefix))
+		if err != nil {
+			return err
+		}
+		p.PruneMode = prune.mode
+		p.Changes = append(p.Changes, deletes...)
+	}
+
+	printPlan(p)
+
+	if err := p.Write(planOutput); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "\nPlan written to %s\n", planOutput)
+
+	return nil
+}
+
+// planTarget returns the client's target. Plans pin the current version of
+// every secret, so they need a KV v2 mount.
+func planTarget(ctx context.Context, client *openbao.Client) (target.VersionedTarget, error) {
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return versionedTarget(kv, "import plan")
+}
+
+// planSecret classifies a single secret against the current state of its
+// destination path in OpenBao.
+func planSecret(ctx context.Context, kv target.VersionedTarget, secret source.Secret, destPath string, skipExisting bool) (plan.Change, error) {
+	change := plan.Change{
+		Path:       destPath,
+		SourcePath: secret.Path,
+	}
+
+	current, err := kv.ReadVersion(ctx, change.Path)
+	if err != nil {
+		return change, err
+	}
+	if current != nil {
+		change.CurrentVersion = current.Version
+	}
+
+	// A deleted current version is recreated like a missing secret
+	if current == nil || current.Data == nil {
+		change.Action = plan.ActionCreate
+		change.Added, _, _ = plan.Diff(secret.Data, nil)
+		return change, nil
+	}
+
+	change.Added, change.Removed, change.Changed = plan.Diff(secret.Data, current.Data)
+	switch {
+	case len(change.Added) == 0 && len(change.Removed) == 0 && len(change.Changed) == 0:
+		change.Action = plan.ActionUnchanged
+	case skipExisting:
+		change.Action = plan.ActionSkip
+	default:
+		change.Action = plan.ActionUpdate
+	}
+
+	return change, nil
+}
+
+// printPlan prints the planned changes with key names only.
+func printPlan(p *plan.Plan) {
+	fmt.Printf("Plan for %s (mount %s):\n\n", p.Address, p.Mount)
+
+	symbols := map[plan.Action]string{
+		plan.ActionCreate:    "+",
+		plan.ActionUpdate:    "~",
+		plan.ActionUnchanged: "=",
+		plan.ActionSkip:      "!",
+		plan.ActionDelete:    "-",
+	}
+
+	for _, c := range p.Changes {
+		if c.Action == plan.ActionDelete {
+			fmt.Printf("  %s %s (%s)\n", symbols[c.Action], c.Path, p.PruneMode)
+			continue
+		}
+		fmt.Printf("  %s %s (%s)\n", symbols[c.Action], c.Path, c.Action)
+		if c.Action == plan.ActionUnchanged {
+			continue
+		}
+		for _, k := range c.Added {
+			fmt.Printf("      + %s\n", k)
+		}
+		for _, k := range c.Removed {
+			fmt.Printf("      - %s\n", k)
+		}
+		for _, k := range c.Changed {
+			fmt.Printf("      ~ %s: (value masked)\n", k)
+		}
+	}
+
+	summary := p.Summary()
+	fmt.Printf("\nPlan: %d to create, %d to update, %d unchanged, %d to skip",
+		summary[plan.ActionCreate], summary[plan.ActionUpdate], summary[plan.ActionUnchanged], summary[plan.ActionSkip])
+	if p.PruneMode != "" {
+		fmt.Printf(", %d to %s", summary[plan.ActionDelete], p.PruneMode)
+	}
+	fmt.Println()
+}
+
+func runImportApply(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	p, err := plan.Read(applyPlan)
+	if err != nil {
+		return err
+	}
+
+	prune := &pruner{mode: p.PruneMode}
+	if p.Summary()[plan.ActionDelete] > 0 && p.PruneMode != pruneDelete && p.PruneMode != pruneDestroy {
+		return fmt.Errorf("plan has secrets to prune but an invalid prune mode %q", p.PruneMode)
+	}
+
+	input := applyInput
+	if input == "" {
+		input = p.Input
+	}
+
+	// The plan only pins the export file by digest; the values come from it
+	digest, err := plan.FileDigest(input)
+	if err != nil {
+		return err
+	}
+	if digest != p.InputDigest {
+		return fmt.Errorf("export file %s changed since planning; run import plan again", input)
+	}
+
+	fmt.Fprintf(os.Stderr, "Reading export file: %s\n", input)
+	export, err := schema.ValidateFileWith(input, exportDecrypter(applyIdentities))
+	if err != nil {
+		return fmt.Errorf("failed to read/validate export file: %w", err)
+	}
+
+	if p.TransformFile != "" {
+		digest, err := plan.FileDigest(p.TransformFile)
+		if err != nil {
+			return err
+		}
+		if digest != p.TransformFileDigest {
+			return fmt.Errorf("transforms file %s changed since planning; run import plan again", p.TransformFile)
+		}
+	}
+	transforms, err := (&transformFlags{specs: p.Transforms, file: p.TransformFile}).load()
+	if err != nil {
+		return err
+	}
+	if err := transformSecrets(transforms, export.Secrets); err != nil {
+		return err
+	}
+
+	secrets := make(map[string]source.Secret, len(export.Secrets))
+	for _, secret := range export.Secrets {
+		secrets[secret.Path] = secret
+	}
+	for _, c := range p.Changes {
+		if _, ok := secrets[c.SourcePath]; !ok && (c.Action == plan.ActionCreate || c.Action == plan.ActionUpdate) {
+			return fmt.Errorf("secret %s is in the plan but not in the export file", c.SourcePath)
+		}
+	}
+
+	retryer, err := applyRetry.openbaoRetryer()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectOpenBao(ctx, applyOpenBaoAddr, p.Mount, p.Namespace, applyHeaders, applyTLSSkipVerify, &applyAuth, retryer)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if client.Address() != p.Address {
+		fmt.Fprintf(os.Stderr, "  Warning: plan was computed against %s\n", p.Address)
+	}
+
+	kv, err := planTarget(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to apply anything if the destination drifted since planning
+	fmt.Fprintf(os.Stderr, "Checking %d secrets for drift...\n", len(p.Changes))
+	var drifted []string
+	for _, c := range p.Changes {
+		current, err := kv.ReadVersion(ctx, c.Path)
+		if err != nil {
+			return err
+		}
+		version := 0
+		if current != nil {
+			version = current.Version
+		}
+		if version != c.CurrentVersion {
+			drifted = append(drifted, fmt.Sprintf("%s (planned version %d, now %d)", c.Path, c.CurrentVersion, version))
+		}
+	}
+	if len(drifted) > 0 {
+		fmt.Fprintf(os.Stderr, "\nDestination drifted since planning:\n  %s\n", strings.Join(drifted, "\n  "))
+		return fmt.Errorf("%d secrets drifted since planning; run import plan again", len(drifted))
+	}
+
+	fmt.Fprintf(os.Stderr, "\nApplying plan...\n")
+
+	var created, updated, unchanged, skipped, pruned, failed int
+	for _, c := range p.Changes {
+		switch c.Action {
+		case plan.ActionUnchanged:
+			unchanged++
+			continue
+		case plan.ActionSkip:
+			skipped++
+			continue
+		case plan.ActionDelete:
+			// Deletes follow the writes and are only done if all succeeded
+			if failed > 0 {
+				fmt.Fprintf(os.Stderr, "  Not pruning %s: earlier changes failed\n", c.Path)
+				continue
+			}
+			if err := prune.remove(ctx, kv, c.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "  Error applying %s: %v\n", c.Path, err)
+				failed++
+				continue
+			}
+			pruned++
+			fmt.Fprintf(os.Stderr, "  ✓ %s %s\n", p.PruneMode, c.Path)
+			continue
+		}
+
+		if err := kv.WriteCAS(ctx, c.Path, secrets[c.SourcePath].Data, c.CurrentVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "  Error applying %s: %v\n", c.Path, err)
+			failed++
+			continue
+		}
+
+		if c.Action == plan.ActionCreate {
+			created++
+		} else {
+			updated++
+		}
+		fmt.Fprintf(os.Stderr, "  ✓ %s %s\n", c.Action, c.Path)
+	}
+
+	fmt.Println()
+	fmt.Println("Apply complete:")
+	fmt.Printf("  Created:   %d\n", created)
+	fmt.Printf("  Updated:   %d\n", updated)
+	fmt.Printf("  Unchanged: %d\n", unchanged)
+	fmt.Printf("  Skipped:   %d\n", skipped)
+	if p.PruneMode != "" {
+		fmt.Printf("  Pruned:    %d\n", pruned)
+	}
+	fmt.Printf("  Failed:    %d\n", failed)
+	printRetryStats(os.Stdout, "OpenBao", client.RetryStats())
+
+	if failed > 0 {
+		return fmt.Errorf("%d secrets failed to apply", failed)
+	}
+
+	return nil
+}
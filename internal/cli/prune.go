@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/plan"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/rewrite"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// Prune modes of --prune-mode.
+const (
+	pruneDelete  = "delete"
+	pruneDestroy = "destroy"
+)
+
+// pruneFlags holds the flags that remove destination secrets missing from
+// the export file.
+type pruneFlags struct {
+	enabled    bool
+	mode       string
+	protect    []string
+	wholeMount bool
+}
+
+// register adds the prune flags to cmd.
+func (f *pruneFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.enabled, "prune", false, "Remove secrets under --path-prefix that are not in the export file")
+	cmd.Flags().StringVar(&f.mode, "prune-mode", pruneDelete, "How --prune removes secrets: delete (soft delete, can be undeleted) or destroy (all versions and metadata)")
+	cmd.Flags().StringArrayVar(&f.protect, "prune-protect", []string{}, "Glob of destination paths --prune never removes (can be specified multiple times)")
+	cmd.Flags().BoolVar(&f.wholeMount, "prune-whole-mount", false, "Allow --prune without --path-prefix, removing every secret of the mount that is not in the export file")
+}
+
+// load validates the flags and returns the pruner, or nil if --prune is not
+// set. pathPrefix is the normalized --path-prefix; pruning without one
+// covers the whole mount and must be asked for explicitly.
+func (f *pruneFlags) load(cmd *cobra.Command, pathPrefix string) (*pruner, error) {
+	if !f.enabled {
+		for _, name := range []string{"prune-mode", "prune-protect", "prune-whole-mount"} {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s requires --prune", name)
+			}
+		}
+		return nil, nil
+	}
+
+	if pathPrefix == "" && !f.wholeMount {
+		return nil, fmt.Errorf("--prune without --path-prefix would prune the whole mount; set --path-prefix or pass --prune-whole-mount")
+	}
+	if pathPrefix != "" && f.wholeMount {
+		return nil, fmt.Errorf("--prune-whole-mount cannot be used with --path-prefix")
+	}
+
+	if f.mode != pruneDelete && f.mode != pruneDestroy {
+		return nil, fmt.Errorf("invalid prune mode %q (expected delete or destroy)", f.mode)
+	}
+
+	protect, err := filter.NewPathFilter(nil, f.protect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --prune-protect pattern: %w", err)
+	}
+
+	return &pruner{mode: f.mode, protect: protect}, nil
+}
+
+// pruner removes the destination secrets that are missing from the export
+// file, so the destination subtree mirrors it.
+type pruner struct {
+	mode    string             // pruneDelete or pruneDestroy
+	protect *filter.PathFilter // Excludes the protected paths
+}
+
+// keepPaths returns the destination paths the secrets are written to, which
+// are never pruned.
+func keepPaths(secrets []source.Secret, rewrites map[string]rewrite.Result, pathPrefix string) map[string]bool {
+	keep := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		keep[pathPrefix+rewrites[secret.Path].Path] = true
+	}
+	return keep
+}
+
+// candidates lists the secrets below pathPrefix recursively and returns a
+// delete change for every one that is neither in keep nor protected. In
+// delete mode, secrets whose current version is already deleted are left
+// out.
+func (p *pruner) candidates(ctx context.Context, client *openbao.Client, pathPrefix string, keep map[string]bool) ([]plan.Change, error) {
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := kv.(target.VersionedTarget); !ok && p.mode == pruneDelete {
+		return nil, fmt.Errorf("--prune-mode delete requires a KV v2 mount (target is %s); KV v1 secrets can only be pruned with --prune-mode destroy", kv.Name())
+	}
+
+	fmt.Fprintf(os.Stderr, "Listing secrets under %q to prune...\n", pathPrefix)
+	paths, err := client.ListSecrets(ctx, pathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []plan.Change
+	for _, path := range paths {
+		if keep[path] {
+			continue
+		}
+		if !p.protect.Matches(path) {
+			fmt.Fprintf(os.Stderr, "  Protected: %s\n", path)
+			continue
+		}
+
+		current, err := lookupSecret(ctx, kv, path)
+		if err != nil {
+			return nil, err
+		}
+		if p.mode == pruneDelete && (current == nil || current.Data == nil) {
+			continue
+		}
+
+		changes = append(changes, plan.Change{
+			Path:           path,
+			Action:         plan.ActionDelete,
+			CurrentVersion: currentVersion(current),
+		})
+	}
+
+	return changes, nil
+}
+
+// printPrune prints the secrets a prune would remove.
+func printPrune(changes []plan.Change, mode string) {
+	if len(changes) == 0 {
+		fmt.Println("\nNothing to prune")
+		return
+	}
+
+	fmt.Printf("\nSecrets to %s (missing from the export file):\n", mode)
+	for _, c := range changes {
+		fmt.Printf("  - %s\n", c.Path)
+	}
+}
+
+// confirm asks before anything is imported whether changes may be pruned.
+// Pruning is never done unconfirmed; unattended runs use "import plan
+// --prune" and "import apply" instead.
+func (p *pruner) confirm(changes []plan.Change) error {
+	confirmed := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("After importing, %s these %d secrets?", p.mode, len(changes)),
+	}
+	if err := survey.AskOne(prompt, &confirmed); err != nil {
+		return fmt.Errorf("failed to confirm prune (use import plan --prune and import apply to prune unattended): %w", err)
+	}
+	if !confirmed {
+		return fmt.Errorf("prune was not confirmed; nothing was imported")
+	}
+	return nil
+}
+
+// remove deletes or destroys the secret at path.
+func (p *pruner) remove(ctx context.Context, kv target.Target, path string) error {
+	if p.mode == pruneDestroy {
+		if versioned, ok := kv.(target.VersionedTarget); ok {
+			return versioned.Destroy(ctx, path)
+		}
+	}
+	// KV v1 deletes are permanent, so they destroy as well
+	return kv.Delete(ctx, path)
+}
+
+// run removes the confirmed secrets and reports the outcome. It does
+// nothing on a nil pruner.
+func (p *pruner) run(ctx context.Context, client *openbao.Client, changes []plan.Change) error {
+	if p == nil || len(changes) == 0 {
+		return nil
+	}
+
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nPruning %d secrets (%s)...\n", len(changes), p.mode)
+
+	var removed, failed int
+	for _, c := range changes {
+		if err := p.remove(ctx, kv, c.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "  Error pruning %s: %v\n", c.Path, err)
+			failed++
+			continue
+		}
+		removed++
+		fmt.Fprintf(os.Stderr, "  ✓ %s %s\n", p.mode, c.Path)
+	}
+
+	fmt.Println()
+	fmt.Println("Prune complete:")
+	fmt.Printf("  Removed: %d\n", removed)
+	fmt.Printf("  Failed:  %d\n", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d secrets failed to prune", failed)
+	}
+
+	return nil
+}
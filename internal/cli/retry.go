@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+// retryFlags holds the retry and rate limiting flags shared by the commands
+// that call OpenBao or a source API.
+type retryFlags struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	jitter      float64
+	retryOn     []string
+
+	rateLimit       float64
+	rateBurst       int
+	sourceRateLimit float64
+	sourceRateBurst int
+}
+
+// register adds the retry policy flags to cmd, plus the OpenBao and/or source
+// rate limit flags.
+func (f *retryFlags) register(cmd *cobra.Command, withOpenBao, withSource bool) {
+	def := retry.DefaultPolicy()
+
+	cmd.Flags().IntVar(&f.maxAttempts, "retry-max-attempts", def.MaxAttempts, "Maximum attempts per request, including the first (1 disables retries)")
+	cmd.Flags().DurationVar(&f.backoff, "retry-backoff", def.InitialBackoff, "Delay before the first retry; doubled on every further retry")
+	cmd.Flags().DurationVar(&f.maxBackoff, "retry-max-backoff", def.MaxBackoff, "Maximum delay between retries")
+	cmd.Flags().Float64Var(&f.jitter, "retry-jitter", def.Jitter, "Randomize retry delays by up to this fraction (0-1)")
+	cmd.Flags().StringSliceVar(&f.retryOn, "retry-on", def.RetryOn, "Retryable error classes: 429, 5xx, network")
+
+	if withOpenBao {
+		cmd.Flags().Float64Var(&f.rateLimit, "rate-limit", 0, "Maximum OpenBao requests per second (0 for unlimited)")
+		cmd.Flags().IntVar(&f.rateBurst, "rate-burst", 0, "OpenBao rate limiter burst size (default: the rate limit)")
+	}
+	if withSource {
+		cmd.Flags().Float64Var(&f.sourceRateLimit, "source-rate-limit", 0, "Maximum source API requests per second (0 for unlimited)")
+		cmd.Flags().IntVar(&f.sourceRateBurst, "source-rate-burst", 0, "Source rate limiter burst size (default: the source rate limit)")
+	}
+}
+
+// policy returns the validated retry policy.
+func (f *retryFlags) policy() (retry.Policy, error) {
+	p := retry.Policy{
+		MaxAttempts:    f.maxAttempts,
+		InitialBackoff: f.backoff,
+		MaxBackoff:     f.maxBackoff,
+		Jitter:         f.jitter,
+		RetryOn:        f.retryOn,
+	}
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("invalid retry settings: %w", err)
+	}
+	return p, nil
+}
+
+// openbaoRetryer returns the retryer for OpenBao requests.
+func (f *retryFlags) openbaoRetryer() (*retry.Retryer, error) {
+	p, err := f.policy()
+	if err != nil {
+		return nil, err
+	}
+	return retry.New(p, retry.NewLimiter(f.rateLimit, burst(f.rateLimit, f.rateBurst)), openbao.StatusCode), nil
+}
+
+// sourceRetryer returns the retryer for source API calls.
+func (f *retryFlags) sourceRetryer() (*retry.Retryer, error) {
+	p, err := f.policy()
+	if err != nil {
+		return nil, err
+	}
+	return retry.New(p, retry.NewLimiter(f.sourceRateLimit, burst(f.sourceRateLimit, f.sourceRateBurst)), nil), nil
+}
+
+// burst returns the configured burst, defaulting to the rate rounded up.
+func burst(rate float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+	return int(math.Ceil(rate))
+}
+
+// printRetryStats prints the retry counters of a retryer if it did any work.
+func printRetryStats(w io.Writer, label string, stats retry.Stats) {
+	if stats.Retries == 0 && stats.Throttled == 0 && stats.RateLimitWait == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s retries:   %d\n", label, stats.Retries)
+	fmt.Fprintf(w, "  %s throttled: %d\n", label, stats.Throttled)
+	if stats.RateLimitWait > 0 {
+		fmt.Fprintf(w, "  %s rate limit wait: %s\n", label, stats.RateLimitWait.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/rewrite"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+// rewriteFlags holds the path rewrite flags shared by the commands that read
+// or write secret paths.
+type rewriteFlags struct {
+	rules []string
+	file  string
+}
+
+// register adds the rewrite flags to cmd.
+func (f *rewriteFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.rules, "rewrite", []string{}, "Path rewrite rule, applied in order after --rewrite-file (can be specified multiple times, format: 'regex:<re>=><repl>' with $1 or ${1} group references, 'glob:<glob>=><template>', 'case:lower|upper', 'sanitize[:<char>]')")
+	cmd.Flags().StringVar(&f.file, "rewrite-file", "", "YAML file of path rewrite rules")
+}
+
+// load returns the rules from --rewrite-file followed by the --rewrite rules.
+func (f *rewriteFlags) load() (rewrite.Rules, error) {
+	var rules rewrite.Rules
+	if f.file != "" {
+		fileRules, err := rewrite.LoadFile(f.file)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	flagRules, err := rewrite.ParseAll(f.rules)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, flagRules...), nil
+}
+
+// secretPaths returns the path of every secret.
+func secretPaths(secrets []source.Secret) []string {
+	paths := make([]string, len(secrets))
+	for i, secret := range secrets {
+		paths[i] = secret.Path
+	}
+	return paths
+}
+
+// secretInfoPaths returns the path of every listed secret.
+func secretInfoPaths(infos []source.SecretInfo) []string {
+	paths := make([]string, len(infos))
+	for i, info := range infos {
+		paths[i] = info.Path
+	}
+	return paths
+}
+
+// renameSecrets replaces the path of every secret with its rewritten path.
+func renameSecrets(secrets []source.Secret, rewrites map[string]rewrite.Result) {
+	for i := range secrets {
+		secrets[i].Path = rewrites[secrets[i].Path].Path
+	}
+}
+
+// streamRewriter rewrites the paths of secrets as they are streamed from a
+// source, failing any secret whose path collides with an earlier one.
+type streamRewriter struct {
+	rules rewrite.Rules
+	seen  map[string]string // Rewritten path to original path
+}
+
+func newStreamRewriter(rules rewrite.Rules) *streamRewriter {
+	return &streamRewriter{rules: rules, seen: make(map[string]string)}
+}
+
+// rewrite rewrites the path of secret in place.
+func (r *streamRewriter) rewrite(secret *source.Secret) error {
+	result, err := r.rules.Rewrite(secret.Path)
+	if err != nil {
+		return err
+	}
+	if other, ok := r.seen[result.Path]; ok && other != secret.Path {
+		return fmt.Errorf("rewrite rules map %s and %s to the same destination %s", other, secret.Path, result.Path)
+	}
+	r.seen[result.Path] = secret.Path
+	secret.Path = result.Path
+	return nil
+}
+
+// printFiredRules prints the rules that rewrote a path for dry-run output.
+func printFiredRules(result rewrite.Result) {
+	for _, rule := range result.Fired {
+		fmt.Printf("    Rule: %s\n", rule)
+	}
+}
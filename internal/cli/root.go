@@ -4,11 +4,19 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+
 	// Register sources
 	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/aws"
+	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/azure"
+	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/file"
+	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/gcp"
+	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/kubernetes"
+	_ "github.com/GlueOps/openbao-secrets-importer/pkg/source/vault"
 )
 
 var (
@@ -30,6 +38,11 @@ Workflow:
   3. Validate the export file (optional)
   4. Import secrets from the file to OpenBao
 
+Or migrate secrets directly from a source to OpenBao without an intermediate file.
+
+Any flag can also be set from an OPENBAO_SECRETS_IMPORTER_<FLAG> environment
+variable or a named profile in the config file (--config, --profile).
+
 Examples:
   # List secrets from AWS Secrets Manager
   openbao-secrets-importer list --source aws-secrets-manager --include "prod/**"
@@ -41,7 +54,10 @@ Examples:
   openbao-secrets-importer validate --input secrets.json
 
   # Import secrets to OpenBao
-  openbao-secrets-importer import --input secrets.json --openbao-addr https://openbao:8200 --openbao-token hvs.xxx`,
+  openbao-secrets-importer import --input secrets.json --openbao-addr https://openbao:8200 --openbao-token hvs.xxx
+
+  # Migrate secrets directly without an export file
+  openbao-secrets-importer migrate --source aws-secrets-manager --openbao-addr https://openbao:8200 --openbao-token hvs.xxx`,
 }
 
 // versionCmd shows version information.
@@ -60,8 +76,24 @@ var sourcesCmd = &cobra.Command{
 	Use:   "sources",
 	Short: "List available secret sources",
 	Run: func(cmd *cobra.Command, args []string) {
+		names := source.List()
+		sort.Strings(names)
+
+		width := 0
+		for _, name := range names {
+			if len(name) > width {
+				width = len(name)
+			}
+		}
+
 		fmt.Println("Available sources:")
-		fmt.Println("  aws-secrets-manager  - AWS Secrets Manager")
+		for _, name := range names {
+			src, err := source.Get(name)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("  %-*s  - %s\n", width, name, src.Description())
+		}
 	},
 }
 
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+// configureSource gets the named source and configures it from the common
+// source flags. sourceOpts are "key=value" strings from --source-opt and are
+// passed to Configure as string options.
+func configureSource(ctx context.Context, name, region, defaultKey string, sourceOpts []string) (source.Source, error) {
+	// Get the source
+	src, err := source.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	// Configure the source
+	opts := make(map[string]interface{})
+	for _, o := range sourceOpts {
+		parts := strings.SplitN(o, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid source option: %s (expected 'key=value')", o)
+		}
+		opts[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	if region != "" {
+		opts["region"] = region
+	}
+	if defaultKey != "" {
+		opts["non_json_key"] = defaultKey
+	}
+
+	if err := src.Configure(ctx, opts); err != nil {
+		return nil, fmt.Errorf("failed to configure source: %w", err)
+	}
+
+	return src, nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/transform"
+)
+
+// transformFlags holds the secret data transform flags shared by the
+// commands that read or write secret values.
+type transformFlags struct {
+	specs []string
+	file  string
+}
+
+// register adds the transform flags to cmd.
+func (f *transformFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.specs, "transform", []string{}, "Secret data transform, applied to every secret in order after --transform-file (can be specified multiple times, format: 'rename:<from>=<to>', 'drop:<key-glob>', 'flatten[:<sep>]', 'unflatten[:<sep>]', 'base64:<key-glob>')")
+	cmd.Flags().StringVar(&f.file, "transform-file", "", "YAML file of secret data transforms")
+}
+
+// load returns the rules from --transform-file followed by the --transform
+// rules.
+func (f *transformFlags) load() (transform.Rules, error) {
+	var rules transform.Rules
+	if f.file != "" {
+		fileRules, err := transform.LoadFile(f.file)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	flagRules, err := transform.ParseAll(f.specs)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, flagRules...), nil
+}
+
+// transformSecrets transforms the data of every secret in place.
+func transformSecrets(rules transform.Rules, secrets []source.Secret) error {
+	for i := range secrets {
+		if err := rules.Apply(&secrets[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
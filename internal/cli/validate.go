@@ -19,17 +19,25 @@ This checks:
   - Secret paths and data are valid
   - Metadata consistency
 
+Encrypted export files are decrypted with --identity (age identity file) or,
+for passphrase-encrypted files, with the passphrase from ` + passphraseEnv + ` or a prompt.
+
 Examples:
-  openbao-secrets-importer validate --input secrets.json`,
+  openbao-secrets-importer validate --input secrets.json
+
+  # Validate an encrypted export file
+  openbao-secrets-importer validate --input secrets.json --identity ~/.config/age/key.txt`,
 	RunE: runValidate,
 }
 
 var (
-	validateInput string
+	validateInput      string
+	validateIdentities []string
 )
 
 func init() {
 	validateCmd.Flags().StringVarP(&validateInput, "input", "f", "", "Input file path")
+	validateCmd.Flags().StringArrayVar(&validateIdentities, "identity", []string{}, "age identity file for encrypted export files (can be specified multiple times)")
 
 	validateCmd.MarkFlagRequired("input")
 
@@ -37,7 +45,7 @@ func init() {
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
-	export, err := schema.ValidateFile(validateInput)
+	export, err := schema.ValidateFileWith(validateInput, exportDecrypter(validateIdentities))
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
@@ -54,6 +62,13 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Region:         %s\n", export.Metadata.Region)
 	}
 
+	if enc := export.Metadata.Encryption; enc != nil {
+		fmt.Printf("  Encryption:     %s\n", enc.Method)
+		for _, r := range enc.Recipients {
+			fmt.Printf("    - %s\n", r)
+		}
+	}
+
 	if len(export.Metadata.IncludePatterns) > 0 {
 		fmt.Printf("  Include:        %v\n", export.Metadata.IncludePatterns)
 	}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/schema"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/verify"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify OpenBao contents against an export file",
+	Long: `Verify that OpenBao holds the secrets of an export file.
+
+Every secret in the export file is read from OpenBao and compared key by key.
+Missing secrets, missing and extra keys and value mismatches are reported.
+Values are never printed; mismatches show a short HMAC-SHA256 fingerprint
+of each side, keyed per run so it cannot be brute-forced.
+
+Exits with a non-zero status if any secret does not match.
+
+Examples:
+  # Verify after an import
+  openbao-secrets-importer verify \
+    --input secrets.json \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --mount secret \
+    --path-prefix "aws-imported/"
+
+  # Write a machine-readable report
+  openbao-secrets-importer verify \
+    --input secrets.json \
+    --openbao-addr https://openbao:8200 \
+    --openbao-token hvs.xxx \
+    --report verify-report.json`,
+	RunE: runVerify,
+}
+
+var (
+	verifyInput         string
+	verifyOpenBaoAddr   string
+	verifyAuth          authFlags
+	verifyMount         string
+	verifyNamespace     string
+	verifyHeaders       []string
+	verifyPathPrefix    string
+	verifyParallelism   int
+	verifyTLSSkipVerify bool
+	verifyIdentities    []string
+	verifyReport        string
+	verifyRewrite       rewriteFlags
+	verifyTransform     transformFlags
+)
+
+func init() {
+	verifyCmd.Flags().StringVarP(&verifyInput, "input", "f", "", "Input file path")
+	verifyCmd.Flags().StringVar(&verifyOpenBaoAddr, "openbao-addr", "", "OpenBao server address (e.g., https://openbao:8200)")
+	verifyAuth.register(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyMount, "mount", "secret", "KV mount path (v1 or v2, detected automatically)")
+	verifyCmd.Flags().StringVar(&verifyNamespace, "namespace", "", "OpenBao namespace to log in to and verify")
+	verifyCmd.Flags().StringArrayVar(&verifyHeaders, "header", []string{}, "Custom HTTP header (can be specified multiple times, format: 'Key: Value')")
+	verifyCmd.Flags().StringVar(&verifyPathPrefix, "path-prefix", "", "Prefix that was prepended to all secret paths on import")
+	verifyCmd.Flags().IntVar(&verifyParallelism, "parallelism", 5, "Number of parallel read workers")
+	verifyCmd.Flags().BoolVar(&verifyTLSSkipVerify, "tls-skip-verify", false, "Skip TLS certificate verification")
+	verifyCmd.Flags().StringArrayVar(&verifyIdentities, "identity", []string{}, "age identity file for encrypted export files (can be specified multiple times)")
+	verifyCmd.Flags().StringVar(&verifyReport, "report", "", "Write a JSON report to this file")
+	verifyRewrite.register(verifyCmd)
+	verifyTransform.register(verifyCmd)
+
+	verifyCmd.MarkFlagRequired("input")
+	verifyCmd.MarkFlagRequired("openbao-addr")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if verifyParallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1")
+	}
+
+	rules, err := verifyRewrite.load()
+	if err != nil {
+		return err
+	}
+	transforms, err := verifyTransform.load()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Reading export file: %s\n", verifyInput)
+	export, err := schema.ValidateFileWith(verifyInput, exportDecrypter(verifyIdentities))
+	if err != nil {
+		return fmt.Errorf("failed to read/validate export file: %w", err)
+	}
+
+	if err := transformSecrets(transforms, export.Secrets); err != nil {
+		return err
+	}
+
+	rewrites, err := rules.RewriteAll(secretPaths(export.Secrets))
+	if err != nil {
+		return err
+	}
+
+	client, err := connectOpenBao(ctx, verifyOpenBaoAddr, verifyMount, verifyNamespace, verifyHeaders, verifyTLSSkipVerify, &verifyAuth, nil)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	kv, err := client.Target(ctx)
+	if err != nil {
+		return err
+	}
+
+	pathPrefix := normalizePathPrefix(verifyPathPrefix)
+
+	hasher, err := verify.NewHasher()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\nVerifying %d secrets with %d workers...\n", len(export.Secrets), verifyParallelism)
+
+	// Results are stored by index so the report follows the export file order
+	results := make([]verify.Result, len(export.Secrets))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < verifyParallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				secret := export.Secrets[i]
+				destPath := pathPrefix + rewrites[secret.Path].Path
+
+				current, err := kv.Read(ctx, destPath)
+				if err != nil {
+					results[i] = verify.Result{
+						Path:       destPath,
+						SourcePath: secret.Path,
+						Status:     verify.StatusError,
+						Error:      err.Error(),
+					}
+					continue
+				}
+				results[i] = verify.Compare(hasher, destPath, secret.Path, secret.Data, current)
+			}
+		}()
+	}
+	for i := range export.Secrets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &verify.Report{
+		Input:      verifyInput,
+		Address:    client.Address(),
+		Mount:      client.Mount(),
+		Namespace:  client.Namespace(),
+		PathPrefix: pathPrefix,
+		VerifiedAt: time.Now().UTC(),
+	}
+	for _, result := range results {
+		report.Add(result)
+	}
+
+	printVerifyReport(report)
+
+	if verifyReport != "" {
+		if err := report.Write(verifyReport); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "\nReport written to %s\n", verifyReport)
+	}
+
+	if drifted := report.Drifted(); drifted > 0 {
+		return fmt.Errorf("verification failed: %d of %d secrets do not match", drifted, report.Summary.Total)
+	}
+
+	return nil
+}
+
+// printVerifyReport prints every secret that did not verify and a summary.
+func printVerifyReport(report *verify.Report) {
+	fmt.Println()
+	for _, r := range report.Results {
+		switch r.Status {
+		case verify.StatusOK:
+			continue
+		case verify.StatusMissing:
+			fmt.Printf("  ✗ %s: missing\n", r.Path)
+		case verify.StatusError:
+			fmt.Printf("  ✗ %s: %s\n", r.Path, r.Error)
+		case verify.StatusMismatch:
+			fmt.Printf("  ✗ %s: mismatch\n", r.Path)
+			for _, k := range r.MissingKeys {
+				fmt.Printf("      missing key: %s\n", k)
+			}
+			for _, k := range r.ExtraKeys {
+				fmt.Printf("      extra key:   %s\n", k)
+			}
+			for _, m := range r.Mismatches {
+				fmt.Printf("      value differs: %s (expected %s, actual %s)\n", m.Key, m.Expected, m.Actual)
+			}
+		}
+	}
+
+	if report.Drifted() > 0 {
+		fmt.Println()
+	}
+	fmt.Println("Verification complete:")
+	fmt.Printf("  OK:       %d\n", report.Summary.OK)
+	fmt.Printf("  Missing:  %d\n", report.Summary.Missing)
+	fmt.Printf("  Mismatch: %d\n", report.Summary.Mismatch)
+	fmt.Printf("  Errors:   %d\n", report.Summary.Error)
+}
@@ -0,0 +1,198 @@
+// Package config defines the config file: named source, target and command
+// profiles holding flag values, so a whole setup can be reused by name.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvPrefix prefixes the environment variables that set flags, e.g.
+// OPENBAO_SECRETS_IMPORTER_OPENBAO_ADDR for --openbao-addr.
+const EnvPrefix = "OPENBAO_SECRETS_IMPORTER_"
+
+// Environment variables selecting the config file and profile.
+const (
+	EnvConfig  = EnvPrefix + "CONFIG"
+	EnvProfile = EnvPrefix + "PROFILE"
+)
+
+// Config is the config file. Settings map flag names (without the leading
+// "--") to values:
+//
+//	default_profile: prod-eu
+//	sources:
+//	  aws-eu:
+//	    source: aws-secrets-manager
+//	    region: eu-west-1
+//	targets:
+//	  bao-prod:
+//	    openbao-addr: https://openbao.example.com:8200
+//	    mount: secret
+//	    header: ["X-Team: platform"]
+//	profiles:
+//	  prod-eu:
+//	    source_profile: aws-eu
+//	    target_profile: bao-prod
+//	    include: ["prod/**"]
+//	    path-prefix: aws/
+type Config struct {
+	// DefaultProfile is used when no profile is selected (optional)
+	DefaultProfile string `yaml:"default_profile"`
+
+	// Sources are named source settings, referenced by profiles
+	Sources map[string]Settings `yaml:"sources"`
+
+	// Targets are named OpenBao target settings, referenced by profiles
+	Targets map[string]Settings `yaml:"targets"`
+
+	// Profiles are the selectable profiles
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile combines a source, a target and further settings.
+type Profile struct {
+	// SourceProfile names an entry of Config.Sources (optional)
+	SourceProfile string `yaml:"source_profile"`
+
+	// TargetProfile names an entry of Config.Targets (optional)
+	TargetProfile string `yaml:"target_profile"`
+
+	// Settings override those of the source and target
+	Settings Settings `yaml:",inline"`
+}
+
+// Settings maps flag names to a scalar value or a list of values.
+type Settings map[string]interface{}
+
+// Values returns the value of key as strings, one per list element.
+func (s Settings) Values(key string) ([]string, error) {
+	switch v := s[key].(type) {
+	case nil:
+		return nil, fmt.Errorf("setting %s has no value", key)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []interface{}, map[string]interface{}:
+				return nil, fmt.Errorf("setting %s: list items must be scalars", key)
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, nil
+	case map[string]interface{}:
+		return nil, fmt.Errorf("setting %s must be a scalar or a list", key)
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// Keys returns the setting names in sorted order.
+func (s Settings) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultPath returns the config file path under the XDG config directory:
+// $XDG_CONFIG_HOME/openbao-secrets-importer/config.yaml, falling back to
+// ~/.config when XDG_CONFIG_HOME is unset.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "openbao-secrets-importer", "config.yaml")
+}
+
+// Find returns the config file to use: path if set, else $EnvConfig, else
+// DefaultPath if it exists. It returns an empty string if there is none.
+func Find(path string) string {
+	if path != "" {
+		return path
+	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+	if path := DefaultPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Load reads a config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Profile returns the merged settings of a profile: its source, then its
+// target, then its own settings, each overriding the previous.
+func (c *Config) Profile(name string) (Settings, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(c.profileNames(), ", "))
+	}
+
+	merged := make(Settings)
+	if profile.SourceProfile != "" {
+		src, ok := c.Sources[profile.SourceProfile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q: source profile %q not found", name, profile.SourceProfile)
+		}
+		for k, v := range src {
+			merged[k] = v
+		}
+	}
+	if profile.TargetProfile != "" {
+		target, ok := c.Targets[profile.TargetProfile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q: target profile %q not found", name, profile.TargetProfile)
+		}
+		for k, v := range target {
+			merged[k] = v
+		}
+	}
+	for k, v := range profile.Settings {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvName returns the environment variable that sets a flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
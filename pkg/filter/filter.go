@@ -2,9 +2,15 @@
 package filter
 
 import (
+	"strings"
+
 	"github.com/gobwas/glob"
 )
 
+// ExcludePrefix marks a pattern as an exclude pattern in a combined pattern
+// list (e.g., "!**/temp/*").
+const ExcludePrefix = "!"
+
 // PathFilter filters paths based on include/exclude glob patterns.
 type PathFilter struct {
 	includes []glob.Glob
@@ -34,6 +40,32 @@ func NewPathFilter(includePatterns, excludePatterns []string) (*PathFilter, erro
 	return f, nil
 }
 
+// CombinePatterns merges include and exclude patterns into a single list
+// suitable for source.Source List/Export calls. Exclude patterns are prefixed
+// with ExcludePrefix.
+func CombinePatterns(includePatterns, excludePatterns []string) []string {
+	patterns := make([]string, 0, len(includePatterns)+len(excludePatterns))
+	patterns = append(patterns, includePatterns...)
+	for _, p := range excludePatterns {
+		patterns = append(patterns, ExcludePrefix+p)
+	}
+	return patterns
+}
+
+// FromPatterns creates a PathFilter from a combined pattern list as produced
+// by CombinePatterns.
+func FromPatterns(patterns []string) (*PathFilter, error) {
+	var includes, excludes []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, ExcludePrefix) {
+			excludes = append(excludes, strings.TrimPrefix(p, ExcludePrefix))
+		} else {
+			includes = append(includes, p)
+		}
+	}
+	return NewPathFilter(includes, excludes)
+}
+
 // Matches returns true if the path matches the filter criteria.
 // A path matches if:
 // - It matches at least one include pattern (or no includes are specified)
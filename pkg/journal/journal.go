@@ -0,0 +1,232 @@
+// Package journal implements the append-only checkpoint journal used to
+// resume interrupted imports.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Version is the current journal format version.
+const Version = "1"
+
+// Status is the recorded outcome for a destination path.
+type Status string
+
+const (
+	// StatusImported means the secret was written.
+	StatusImported Status = "imported"
+
+	// StatusSkipped means the secret was left alone by the conflict settings.
+	StatusSkipped Status = "skipped"
+
+	// StatusFailed means the write failed and should be retried on resume.
+	StatusFailed Status = "failed"
+
+	// StatusConflict means the secret was written concurrently and the
+	// Check-And-Set write lost; it is retried on resume.
+	StatusConflict Status = "conflict"
+)
+
+// Done reports whether a path with this status needs no further work.
+func (s Status) Done() bool {
+	return s == StatusImported || s == StatusSkipped
+}
+
+// Header is the first line of a journal. It identifies the import the
+// journal belongs to.
+type Header struct {
+	// Version is the journal format version
+	Version string `json:"version"`
+
+	// ExportDigest is the digest of the export file being imported
+	ExportDigest string `json:"export_digest"`
+
+	// Address is the OpenBao server address
+	Address string `json:"address"`
+
+	// Mount is the KV mount path
+	Mount string `json:"mount"`
+
+	// Namespace is the OpenBao namespace imported into
+	Namespace string `json:"namespace,omitempty"`
+
+	// PathPrefix is the prefix prepended to all secret paths
+	PathPrefix string `json:"path_prefix,omitempty"`
+
+	// StartedAt is when the import was first started
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Check returns an error if a journal with this header cannot resume the
+// import described by want: it must belong to the same export file and
+// target.
+func (h *Header) Check(want Header) error {
+	if h.ExportDigest != want.ExportDigest {
+		return fmt.Errorf("belongs to a different export file")
+	}
+	if h.Address != want.Address || h.Mount != want.Mount || h.Namespace != want.Namespace || h.PathPrefix != want.PathPrefix {
+		return fmt.Errorf("was written for %s, mount %q, namespace %q and path prefix %q", h.Address, h.Mount, h.Namespace, h.PathPrefix)
+	}
+	return nil
+}
+
+// Entry is a single outcome line.
+type Entry struct {
+	// Path is the destination path
+	Path string `json:"path"`
+
+	// Status is the outcome
+	Status Status `json:"status"`
+
+	// Error is the failure reason for StatusFailed and StatusConflict
+	Error string `json:"error,omitempty"`
+
+	// Time is when the outcome was recorded
+	Time time.Time `json:"time"`
+}
+
+// Journal appends outcome entries to a journal file. It is safe for
+// concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Create starts a new journal at path, replacing any existing one.
+func Create(path string, header Header) (*Journal, error) {
+	header.Version = Version
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+
+	j := &Journal{file: f}
+	if err := j.writeLine(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Open reads an existing journal and opens it for appending. It returns the
+// header and the latest status recorded for each path.
+func Open(path string) (*Journal, *Header, map[string]Status, error) {
+	header, statuses, err := Read(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	// Terminate a truncated last line so new entries start on their own line
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			f.Close()
+			return nil, nil, nil, fmt.Errorf("failed to write journal: %w", err)
+		}
+	}
+
+	return &Journal{file: f}, header, statuses, nil
+}
+
+// Read parses a journal and returns the header and the latest status
+// recorded for each path. A truncated last line, as left by a crash, is
+// ignored.
+func Read(path string) (*Header, map[string]Status, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read journal: %w", err)
+		}
+		return nil, nil, fmt.Errorf("journal %s is empty", path)
+	}
+
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse journal header: %w", err)
+	}
+	if header.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported journal version: %s (expected %s)", header.Version, Version)
+	}
+
+	statuses := make(map[string]Status)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Path == "" {
+			continue
+		}
+		statuses[entry.Path] = entry.Status
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return &header, statuses, nil
+}
+
+// Record appends the outcome for a path. cause is recorded for failures.
+func (j *Journal) Record(path string, status Status, cause error) error {
+	entry := Entry{
+		Path:   path,
+		Status: status,
+		Time:   time.Now().UTC(),
+	}
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+	return j.writeLine(entry)
+}
+
+// Close flushes the journal to disk and closes it.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return j.file.Close()
+}
+
+// writeLine writes v as a single JSON line. Each line is written with one
+// unbuffered write so it survives the process being killed.
+func (j *Journal) writeLine(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHeader is the header the tests create journals with.
+var testHeader = Header{
+	ExportDigest: "sha256:abc",
+	Address:      "https://bao.example.com:8200",
+	Mount:        "secret",
+	Namespace:    "team",
+	PathPrefix:   "imported",
+	StartedAt:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestStatusDone(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusImported, true},
+		{StatusSkipped, true},
+		{StatusFailed, false},
+		{StatusConflict, false},
+		{Status(""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.Done(); got != tt.want {
+				t.Errorf("Status(%q).Done() = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(h *Header)
+		wantErr string
+	}{
+		{name: "same import", modify: func(h *Header) {}},
+		{name: "different start time", modify: func(h *Header) { h.StartedAt = time.Now() }},
+		{name: "different digest", modify: func(h *Header) { h.ExportDigest = "sha256:def" }, wantErr: "different export file"},
+		{name: "different address", modify: func(h *Header) { h.Address = "https://other:8200" }, wantErr: "was written for https://bao.example.com:8200"},
+		{name: "different mount", modify: func(h *Header) { h.Mount = "kv" }, wantErr: `mount "secret"`},
+		{name: "different namespace", modify: func(h *Header) { h.Namespace = "" }, wantErr: `namespace "team"`},
+		{name: "different path prefix", modify: func(h *Header) { h.PathPrefix = "other" }, wantErr: `path prefix "imported"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := testHeader
+			want := testHeader
+			tt.modify(&want)
+
+			err := existing.Check(want)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.journal")
+
+	j, err := Create(path, testHeader)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	records := []struct {
+		path   string
+		status Status
+		cause  error
+	}{
+		{"a", StatusImported, nil},
+		{"b", StatusFailed, errors.New("permission denied")},
+		{"c", StatusConflict, errors.New("check-and-set mismatch")},
+		{"d", StatusSkipped, nil},
+		{"e", StatusFailed, errors.New("timeout")},
+		{"e", StatusImported, nil},
+	}
+	for _, r := range records {
+		if err := j.Record(r.path, r.status, r.cause); err != nil {
+			t.Fatalf("Record(%q) error = %v", r.path, err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	j, header, statuses, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	want := testHeader
+	want.Version = Version
+	if !reflect.DeepEqual(*header, want) {
+		t.Errorf("header = %+v, want %+v", *header, want)
+	}
+	wantStatuses := map[string]Status{
+		"a": StatusImported,
+		"b": StatusFailed,
+		"c": StatusConflict,
+		"d": StatusSkipped,
+		"e": StatusImported,
+	}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("statuses = %v, want %v", statuses, wantStatuses)
+	}
+
+	// The resumed import retries the failed paths and appends their outcome
+	if err := j.Record("b", StatusImported, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := j.Record("c", StatusFailed, errors.New("again")); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	_, statuses, err = Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	wantStatuses["b"] = StatusImported
+	wantStatuses["c"] = StatusFailed
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("statuses after resume = %v, want %v", statuses, wantStatuses)
+	}
+}
+
+func TestOpenTruncatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.journal")
+
+	j, err := Create(path, testHeader)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := j.Record("a", StatusImported, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Simulate a crash in the middle of writing an entry
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"path":"b","stat`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	j, _, statuses, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if want := map[string]Status{"a": StatusImported}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+	if err := j.Record("b", StatusImported, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	_, statuses, err = Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if want := map[string]Status{"a": StatusImported, "b": StatusImported}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses after append = %v, want %v", statuses, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty", content: "", wantErr: "is empty"},
+		{name: "invalid header", content: "not json\n", wantErr: "failed to parse journal header"},
+		{name: "unsupported version", content: `{"version":"0"}` + "\n", wantErr: "unsupported journal version: 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "import.journal")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err := Read(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
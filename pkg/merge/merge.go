@@ -0,0 +1,104 @@
+// Package merge merges the data of a source secret into the data of an
+// existing destination secret key by key, so keys that only exist at the
+// destination are kept.
+package merge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/secretdata"
+)
+
+// Policy decides the value of a key that exists in both secrets with
+// different values.
+type Policy string
+
+const (
+	// PolicySource writes the source value.
+	PolicySource Policy = "source"
+
+	// PolicyDestination keeps the destination value.
+	PolicyDestination Policy = "destination"
+
+	// PolicyFail refuses to merge the secret.
+	PolicyFail Policy = "fail"
+)
+
+// ParsePolicy parses a policy name.
+func ParsePolicy(name string) (Policy, error) {
+	switch p := Policy(name); p {
+	case PolicySource, PolicyDestination, PolicyFail:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid merge policy %q (expected source, destination or fail)", name)
+	}
+}
+
+// Result is the outcome of merging a single secret. Key lists are sorted.
+type Result struct {
+	// Data is the merged secret data
+	Data map[string]interface{}
+
+	// Added are source keys missing at the destination
+	Added []string
+
+	// Overwritten are colliding keys that take the source value
+	Overwritten []string
+
+	// Kept are colliding keys that keep the destination value
+	Kept []string
+
+	// Preserved are destination keys missing from the source
+	Preserved []string
+
+	// Unchanged are keys with the same value in both secrets
+	Unchanged []string
+}
+
+// Changed reports whether the merged data differs from the destination data.
+func (r *Result) Changed() bool {
+	return len(r.Added) > 0 || len(r.Overwritten) > 0
+}
+
+// Merge merges src into dest, resolving keys whose values differ with
+// policy. Neither map is modified.
+func Merge(src, dest map[string]interface{}, policy Policy) (*Result, error) {
+	result := &Result{Data: make(map[string]interface{}, len(src)+len(dest))}
+
+	var collisions []string
+	for k, v := range dest {
+		result.Data[k] = v
+		if _, ok := src[k]; !ok {
+			result.Preserved = append(result.Preserved, k)
+		}
+	}
+	for k, v := range src {
+		cur, ok := dest[k]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, k)
+			result.Data[k] = v
+		case secretdata.Equal(v, cur):
+			result.Unchanged = append(result.Unchanged, k)
+		case policy == PolicyDestination:
+			result.Kept = append(result.Kept, k)
+		case policy == PolicyFail:
+			collisions = append(collisions, k)
+		default:
+			result.Overwritten = append(result.Overwritten, k)
+			result.Data[k] = v
+		}
+	}
+
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return nil, fmt.Errorf("keys differ between source and destination: %s", strings.Join(collisions, ", "))
+	}
+
+	for _, keys := range [][]string{result.Added, result.Overwritten, result.Kept, result.Preserved, result.Unchanged} {
+		sort.Strings(keys)
+	}
+	return result, nil
+}
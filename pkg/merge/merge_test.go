@@ -0,0 +1,156 @@
+package merge
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	src := map[string]interface{}{
+		"user":  "admin",
+		"pass":  "new",
+		"port":  float64(5432),
+		"token": "t",
+	}
+	dest := map[string]interface{}{
+		"user":  "admin",
+		"pass":  "old",
+		"port":  json.Number("5432"),
+		"extra": "kept",
+	}
+
+	tests := []struct {
+		name            string
+		policy          Policy
+		wantData        map[string]interface{}
+		wantAdded       []string
+		wantOverwritten []string
+		wantKept        []string
+		wantChanged     bool
+		wantErr         string
+	}{
+		{
+			name:   "source wins",
+			policy: PolicySource,
+			wantData: map[string]interface{}{
+				"user": "admin", "pass": "new", "port": json.Number("5432"), "token": "t", "extra": "kept",
+			},
+			wantAdded:       []string{"token"},
+			wantOverwritten: []string{"pass"},
+			wantChanged:     true,
+		},
+		{
+			name:   "destination wins",
+			policy: PolicyDestination,
+			wantData: map[string]interface{}{
+				"user": "admin", "pass": "old", "port": json.Number("5432"), "token": "t", "extra": "kept",
+			},
+			wantAdded:   []string{"token"},
+			wantKept:    []string{"pass"},
+			wantChanged: true,
+		},
+		{
+			name:    "fail on differing keys",
+			policy:  PolicyFail,
+			wantErr: "keys differ between source and destination: pass",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Merge(src, dest, tt.policy)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Merge() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(result.Data, tt.wantData) {
+				t.Errorf("Data = %v, want %v", result.Data, tt.wantData)
+			}
+			if !reflect.DeepEqual(result.Added, tt.wantAdded) {
+				t.Errorf("Added = %v, want %v", result.Added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(result.Overwritten, tt.wantOverwritten) {
+				t.Errorf("Overwritten = %v, want %v", result.Overwritten, tt.wantOverwritten)
+			}
+			if !reflect.DeepEqual(result.Kept, tt.wantKept) {
+				t.Errorf("Kept = %v, want %v", result.Kept, tt.wantKept)
+			}
+			if want := []string{"extra"}; !reflect.DeepEqual(result.Preserved, want) {
+				t.Errorf("Preserved = %v, want %v", result.Preserved, want)
+			}
+			if want := []string{"port", "user"}; !reflect.DeepEqual(result.Unchanged, want) {
+				t.Errorf("Unchanged = %v, want %v", result.Unchanged, want)
+			}
+			if got := result.Changed(); got != tt.wantChanged {
+				t.Errorf("Changed() = %v, want %v", got, tt.wantChanged)
+			}
+		})
+	}
+
+	if src["pass"] != "new" || dest["pass"] != "old" || len(src) != 4 || len(dest) != 4 {
+		t.Errorf("Merge() modified its inputs: src = %v, dest = %v", src, dest)
+	}
+}
+
+func TestMergeUnchanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    map[string]interface{}
+		dest   map[string]interface{}
+		policy Policy
+	}{
+		{
+			name:   "equal data",
+			src:    map[string]interface{}{"a": float64(1), "b": map[string]interface{}{"c": "d"}},
+			dest:   map[string]interface{}{"a": json.Number("1"), "b": map[string]interface{}{"c": "d"}},
+			policy: PolicyFail,
+		},
+		{
+			name:   "source subset of destination",
+			src:    map[string]interface{}{"a": "1"},
+			dest:   map[string]interface{}{"a": "1", "b": "2"},
+			policy: PolicySource,
+		},
+		{
+			name:   "only differing keys kept",
+			src:    map[string]interface{}{"a": "new"},
+			dest:   map[string]interface{}{"a": "old"},
+			policy: PolicyDestination,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Merge(tt.src, tt.dest, tt.policy)
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if result.Changed() {
+				t.Errorf("Changed() = true, want false (added %v, overwritten %v)", result.Added, result.Overwritten)
+			}
+			if !reflect.DeepEqual(result.Data, tt.dest) {
+				t.Errorf("Data = %v, want %v", result.Data, tt.dest)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, name := range []string{"source", "destination", "fail"} {
+		p, err := ParsePolicy(name)
+		if err != nil || string(p) != name {
+			t.Errorf("ParsePolicy(%q) = %q, %v", name, p, err)
+		}
+	}
+	if _, err := ParsePolicy("newest"); err == nil || !strings.Contains(err.Error(), "invalid merge policy") {
+		t.Errorf("ParsePolicy(%q) error = %v, want an invalid policy error", "newest", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Package plan defines the import plan file: a reviewable change set that is
+// computed against the current OpenBao state and applied later.
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/secretdata"
+)
+
+// Version is the current plan file version.
+const Version = "1.0"
+
+// Action is what applying a plan does to a single secret.
+type Action string
+
+const (
+	// ActionCreate writes a secret that does not exist yet.
+	ActionCreate Action = "create"
+
+	// ActionUpdate writes a new version of a secret whose data differs.
+	ActionUpdate Action = "update"
+
+	// ActionUnchanged leaves a secret whose data already matches.
+	ActionUnchanged Action = "unchanged"
+
+	// ActionSkip leaves an existing secret because of the conflict settings.
+	ActionSkip Action = "skip"
+
+	// ActionDelete deletes or destroys, depending on Plan.PruneMode, a
+	// destination secret that is missing from the export file.
+	ActionDelete Action = "delete"
+)
+
+// Plan is the on-disk plan file. It never contains secret values: the data
+// to write is read again from the export file, which is pinned by its digest.
+type Plan struct {
+	// Version is the plan file version
+	Version string `json:"version"`
+
+	// CreatedAt is when the plan was computed
+	CreatedAt time.Time `json:"created_at"`
+
+	// Input is the export file the plan was computed from
+	Input string `json:"input"`
+
+	// InputDigest is the SHA-256 digest of the export file ("sha256:<hex>")
+	InputDigest string `json:"input_digest"`
+
+	// Address is the OpenBao address the plan was computed against
+	Address string `json:"address"`
+
+	// Mount is the KV v2 mount path
+	Mount string `json:"mount"`
+
+	// Namespace is the OpenBao namespace
+	Namespace string `json:"namespace,omitempty"`
+
+	// PathPrefix is the prefix prepended to all secret paths
+	PathPrefix string `json:"path_prefix,omitempty"`
+
+	// TransformFile is the transforms file applied to the secret data
+	TransformFile string `json:"transform_file,omitempty"`
+
+	// TransformFileDigest is the SHA-256 digest of TransformFile
+	TransformFileDigest string `json:"transform_file_digest,omitempty"`
+
+	// Transforms are the transform specs applied after TransformFile
+	Transforms []string `json:"transforms,omitempty"`
+
+	// PruneMode is how ActionDelete changes remove secrets: "delete" or
+	// "destroy"
+	PruneMode string `json:"prune_mode,omitempty"`
+
+	// Changes lists one entry per secret in the export file, followed by
+	// the secrets to prune
+	Changes []Change `json:"changes"`
+}
+
+// Change is the planned action for a single secret.
+type Change struct {
+	// Path is the destination path in OpenBao
+	Path string `json:"path"`
+
+	// SourcePath is the secret path in the export file, empty for
+	// ActionDelete
+	SourcePath string `json:"source_path"`
+
+	// Action is the planned action
+	Action Action `json:"action"`
+
+	// CurrentVersion is the destination version when the plan was computed,
+	// 0 if the secret did not exist
+	CurrentVersion int `json:"current_version"`
+
+	// Added are keys that will be added
+	Added []string `json:"added,omitempty"`
+
+	// Removed are keys that will be removed
+	Removed []string `json:"removed,omitempty"`
+
+	// Changed are keys whose values will change
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Summary counts the changes per action.
+func (p *Plan) Summary() map[Action]int {
+	counts := make(map[Action]int)
+	for _, c := range p.Changes {
+		counts[c.Action]++
+	}
+	return counts
+}
+
+// Write writes the plan file to the specified path.
+func (p *Plan) Write(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+
+	return nil
+}
+
+// Read reads and validates a plan file.
+func Read(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version: %s (expected %s)", p.Version, Version)
+	}
+	if p.Input == "" || p.InputDigest == "" {
+		return nil, fmt.Errorf("plan is missing the export file reference")
+	}
+	if p.Mount == "" {
+		return nil, fmt.Errorf("plan is missing the mount")
+	}
+
+	return &p, nil
+}
+
+// FileDigest returns the SHA-256 digest of a file in InputDigest format.
+func FileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Diff compares the desired secret data with the current data and returns
+// the added, removed and changed keys, each sorted.
+func Diff(desired, current map[string]interface{}) (added, removed, changed []string) {
+	for k, v := range desired {
+		cur, ok := current[k]
+		if !ok {
+			added = append(added, k)
+		} else if !secretdata.Equal(v, cur) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range current {
+		if _, ok := desired[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
@@ -0,0 +1,172 @@
+package plan
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		desired     map[string]interface{}
+		current     map[string]interface{}
+		wantAdded   []string
+		wantRemoved []string
+		wantChanged []string
+	}{
+		{
+			name:    "identical",
+			desired: map[string]interface{}{"a": "1", "b": "2"},
+			current: map[string]interface{}{"a": "1", "b": "2"},
+		},
+		{
+			name:      "new secret",
+			desired:   map[string]interface{}{"b": "2", "a": "1"},
+			current:   nil,
+			wantAdded: []string{"a", "b"},
+		},
+		{
+			name:        "added, removed and changed keys",
+			desired:     map[string]interface{}{"keep": "v", "new": "v", "z": "new", "a": "new"},
+			current:     map[string]interface{}{"keep": "v", "old": "v", "z": "old", "a": "old"},
+			wantAdded:   []string{"new"},
+			wantRemoved: []string{"old"},
+			wantChanged: []string{"a", "z"},
+		},
+		{
+			name:        "all keys removed",
+			desired:     map[string]interface{}{},
+			current:     map[string]interface{}{"b": "2", "a": "1"},
+			wantRemoved: []string{"a", "b"},
+		},
+		{
+			name:    "numbers read back from OpenBao",
+			desired: map[string]interface{}{"port": float64(5432)},
+			current: map[string]interface{}{"port": json.Number("5432")},
+		},
+		{
+			name:        "nested value changed",
+			desired:     map[string]interface{}{"cfg": map[string]interface{}{"a": "1"}},
+			current:     map[string]interface{}{"cfg": map[string]interface{}{"a": "2"}},
+			wantChanged: []string{"cfg"},
+		},
+		{
+			name:        "type changed",
+			desired:     map[string]interface{}{"port": "5432"},
+			current:     map[string]interface{}{"port": json.Number("5432")},
+			wantChanged: []string{"port"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changed := Diff(tt.desired, tt.current)
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("Diff() added = %v, want %v", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("Diff() removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("Diff() changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestFileDigest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	digest := func(path string) string {
+		d, err := FileDigest(path)
+		if err != nil {
+			t.Fatalf("FileDigest(%q) error = %v", path, err)
+		}
+		return d
+	}
+
+	a := write("a.json", `{"secrets": []}`)
+	first := digest(a)
+	if !strings.HasPrefix(first, "sha256:") || len(first) != len("sha256:")+64 {
+		t.Fatalf("FileDigest() = %q, want sha256:<64 hex digits>", first)
+	}
+	if again := digest(a); again != first {
+		t.Errorf("FileDigest() is not stable: %q then %q", first, again)
+	}
+	if copied := digest(write("b.json", `{"secrets": []}`)); copied != first {
+		t.Errorf("FileDigest() of identical content = %q, want %q", copied, first)
+	}
+	if changed := digest(write("a.json", `{"secrets": [] }`)); changed == first {
+		t.Errorf("FileDigest() did not change with the content: %q", changed)
+	}
+
+	if _, err := FileDigest(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Errorf("FileDigest() error = %v, want a read error", err)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	valid := Plan{
+		Version:     Version,
+		Input:       "export.json",
+		InputDigest: "sha256:abc",
+		Address:     "https://bao:8200",
+		Mount:       "secret",
+		Changes: []Change{
+			{Path: "a", SourcePath: "a", Action: ActionCreate, Added: []string{"k"}},
+			{Path: "b", SourcePath: "b", Action: ActionUpdate, CurrentVersion: 3, Changed: []string{"k"}},
+			{Path: "c", Action: ActionDelete, CurrentVersion: 1},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *Plan)
+		wantErr string
+	}{
+		{name: "valid", modify: func(p *Plan) {}},
+		{name: "wrong version", modify: func(p *Plan) { p.Version = "0.9" }, wantErr: "unsupported plan version: 0.9"},
+		{name: "missing input", modify: func(p *Plan) { p.Input = "" }, wantErr: "missing the export file reference"},
+		{name: "missing digest", modify: func(p *Plan) { p.InputDigest = "" }, wantErr: "missing the export file reference"},
+		{name: "missing mount", modify: func(p *Plan) { p.Mount = "" }, wantErr: "missing the mount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			path := filepath.Join(t.TempDir(), "plan.json")
+			if err := p.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			got, err := Read(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, p) {
+				t.Errorf("Read() = %+v, want %+v", *got, p)
+			}
+			want := map[Action]int{ActionCreate: 1, ActionUpdate: 1, ActionDelete: 1}
+			if summary := got.Summary(); !reflect.DeepEqual(summary, want) {
+				t.Errorf("Summary() = %v, want %v", summary, want)
+			}
+		})
+	}
+}
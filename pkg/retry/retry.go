@@ -0,0 +1,308 @@
+// Package retry provides retries with exponential backoff and client-side
+// rate limiting for calls to remote APIs.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Retryable error classes.
+const (
+	// ClassThrottled matches HTTP 429 Too Many Requests.
+	ClassThrottled = "429"
+
+	// ClassServerError matches HTTP 5xx responses except 501 Not Implemented.
+	ClassServerError = "5xx"
+
+	// ClassNetwork matches connection errors without an HTTP response.
+	ClassNetwork = "network"
+)
+
+// Policy configures retries.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first (1 disables retries)
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each delay by up to this fraction (0 to 1)
+	Jitter float64
+
+	// RetryOn lists the retryable error classes (ClassThrottled, ClassServerError, ClassNetwork)
+	RetryOn []string
+}
+
+// DefaultPolicy returns the default retry policy: 3 attempts with backoff
+// from 500ms up to 30s, 20% jitter, retrying throttling, server and network
+// errors.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+		RetryOn:        []string{ClassThrottled, ClassServerError, ClassNetwork},
+	}
+}
+
+// Validate checks the policy for invalid values.
+func (p Policy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be at least 1")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("backoff cannot be negative")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	for _, class := range p.RetryOn {
+		switch class {
+		case ClassThrottled, ClassServerError, ClassNetwork:
+		default:
+			return fmt.Errorf("invalid retry class: %s (expected 429, 5xx or network)", class)
+		}
+	}
+	return nil
+}
+
+// StatusFunc extracts the HTTP status code from an error, if it has one.
+type StatusFunc func(err error) (int, bool)
+
+// Stats counts the work done by a Retryer.
+type Stats struct {
+	// Retries is the number of retried attempts
+	Retries int64
+
+	// Throttled is the number of attempts rejected with HTTP 429
+	Throttled int64
+
+	// RateLimitWait is the total time spent waiting for the rate limiter
+	RateLimitWait time.Duration
+}
+
+// Retryer runs calls with rate limiting and retries. A nil *Retryer runs
+// each call once without limits. It is safe for concurrent use.
+type Retryer struct {
+	policy     Policy
+	limiter    *rate.Limiter
+	statusCode StatusFunc
+
+	retries   atomic.Int64
+	throttled atomic.Int64
+	waited    atomic.Int64
+}
+
+// New creates a Retryer. limiter may be nil to disable rate limiting.
+// statusCode extracts HTTP status codes from the caller's errors in addition
+// to the common SDK error types handled by StatusCode.
+func New(policy Policy, limiter *rate.Limiter, statusCode StatusFunc) *Retryer {
+	return &Retryer{
+		policy:     policy,
+		limiter:    limiter,
+		statusCode: statusCode,
+	}
+}
+
+// NewLimiter creates a token-bucket limiter allowing perSecond requests per
+// second with the given burst. It returns nil if perSecond is not positive.
+func NewLimiter(perSecond float64, burst int) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), burst)
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the
+// policy's attempts are exhausted. Each attempt waits for the rate limiter.
+func (r *Retryer) Do(ctx context.Context, fn func() error) error {
+	if r == nil {
+		return fn()
+	}
+
+	attempts := r.policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if r.limiter != nil {
+			start := time.Now()
+			if werr := r.limiter.Wait(ctx); werr != nil {
+				if err != nil {
+					return err
+				}
+				return werr
+			}
+			r.waited.Add(int64(time.Since(start)))
+		}
+
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		code, hasCode := r.status(err)
+		if hasCode && code == 429 {
+			r.throttled.Add(1)
+		}
+
+		if attempt >= attempts || !r.retryable(err, code, hasCode) {
+			return err
+		}
+
+		r.retries.Add(1)
+		select {
+		case <-time.After(r.backoff(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// Stats returns the counters accumulated so far.
+func (r *Retryer) Stats() Stats {
+	if r == nil {
+		return Stats{}
+	}
+	return Stats{
+		Retries:       r.retries.Load(),
+		Throttled:     r.throttled.Load(),
+		RateLimitWait: time.Duration(r.waited.Load()),
+	}
+}
+
+// status extracts the HTTP status code from err.
+func (r *Retryer) status(err error) (int, bool) {
+	if r.statusCode != nil {
+		if code, ok := r.statusCode(err); ok {
+			return code, true
+		}
+	}
+	return StatusCode(err)
+}
+
+// retryable reports whether err falls in one of the policy's retry classes.
+func (r *Retryer) retryable(err error, code int, hasCode bool) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	for _, class := range r.policy.RetryOn {
+		switch class {
+		case ClassThrottled:
+			if hasCode && code == 429 {
+				return true
+			}
+		case ClassServerError:
+			if hasCode && code >= 500 && code != 501 {
+				return true
+			}
+		case ClassNetwork:
+			if !hasCode && isNetworkError(err) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before retry number attempt (starting at 1).
+func (r *Retryer) backoff(attempt int) time.Duration {
+	delay := r.policy.InitialBackoff
+	for i := 1; i < attempt && delay < r.policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if r.policy.MaxBackoff > 0 && delay > r.policy.MaxBackoff {
+		delay = r.policy.MaxBackoff
+	}
+
+	if r.policy.Jitter > 0 && delay > 0 {
+		// Spread retries of concurrent workers over [delay*(1-j), delay*(1+j)]
+		spread := float64(delay) * r.policy.Jitter
+		delay = time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+	}
+	return delay
+}
+
+// StatusCode extracts an HTTP status code from common SDK error types: AWS
+// (HTTPStatusCode method, with throttling error codes mapped to 429) and
+// gRPC status errors (mapped to the equivalent HTTP status).
+func StatusCode(err error) (int, bool) {
+	// AWS APIs report throttling as 400 with a throttling error code
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) && awsThrottleCodes[apiErr.ErrorCode()] {
+		return 429, true
+	}
+
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		return httpErr.HTTPStatusCode(), true
+	}
+
+	if s, ok := status.FromError(err); ok && s.Code() != codes.OK && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.ResourceExhausted:
+			return 429, true
+		case codes.Unavailable:
+			return 503, true
+		case codes.Internal:
+			return 500, true
+		case codes.DeadlineExceeded:
+			return 504, true
+		}
+	}
+
+	return 0, false
+}
+
+// awsThrottleCodes are the AWS error codes of throttled requests.
+var awsThrottleCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"TooManyRequestsException":               true,
+	"RequestLimitExceeded":                   true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"ProvisionedThroughputExceededException": true,
+}
+
+// isNetworkError reports whether err is a transport-level failure.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// Connection resets are not always wrapped in a net.Error
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "connection refused")
+}
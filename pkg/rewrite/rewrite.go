@@ -0,0 +1,375 @@
+// Package rewrite provides ordered path rewrite rules applied to secret paths
+// on export and import.
+package rewrite
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Rule kinds accepted in rule specs and rules files.
+const (
+	KindRegex    = "regex"
+	KindGlob     = "glob"
+	KindCase     = "case"
+	KindSanitize = "sanitize"
+)
+
+// DefaultSanitizeReplacement replaces characters removed by a sanitize rule
+// without an explicit replacement.
+const DefaultSanitizeReplacement = "_"
+
+// Rule transforms a secret path.
+type Rule interface {
+	// Apply returns the rewritten path and whether the rule fired
+	Apply(path string) (string, bool)
+
+	// String describes the rule for dry-run output
+	String() string
+}
+
+// Rules is an ordered list of rules. Each rule sees the output of the
+// previous one.
+type Rules []Rule
+
+// Result is the outcome of rewriting a single path.
+type Result struct {
+	// Path is the rewritten path
+	Path string
+
+	// Fired lists the rules that fired, in order
+	Fired []Rule
+}
+
+// Rewrite applies every rule to path in order. It fails if the rules reduce
+// the path to nothing.
+func (r Rules) Rewrite(path string) (Result, error) {
+	result := Result{Path: path}
+	for _, rule := range r {
+		rewritten, fired := rule.Apply(result.Path)
+		if !fired {
+			continue
+		}
+		result.Path = rewritten
+		result.Fired = append(result.Fired, rule)
+	}
+
+	if strings.Trim(result.Path, "/") == "" {
+		return Result{}, fmt.Errorf("rewrite rules map %s to an empty path", path)
+	}
+	return result, nil
+}
+
+// RewriteAll rewrites every path and fails if two paths are rewritten to the
+// same destination. The results are keyed by the original path.
+func (r Rules) RewriteAll(paths []string) (map[string]Result, error) {
+	results := make(map[string]Result, len(paths))
+	sources := make(map[string][]string)
+	for _, path := range paths {
+		result, err := r.Rewrite(path)
+		if err != nil {
+			return nil, err
+		}
+		results[path] = result
+		sources[result.Path] = append(sources[result.Path], path)
+	}
+
+	var collisions []string
+	for dest, srcs := range sources {
+		if len(srcs) > 1 {
+			sort.Strings(srcs)
+			collisions = append(collisions, fmt.Sprintf("%s <- %s", dest, strings.Join(srcs, ", ")))
+		}
+	}
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return nil, fmt.Errorf("rewrite rules map several paths to the same destination:\n  %s", strings.Join(collisions, "\n  "))
+	}
+
+	return results, nil
+}
+
+// RegexRule replaces every match of a regular expression. The replacement
+// may reference capture groups as $1 or ${name}; a reference followed by
+// letters, digits or "_" must be braced, e.g. ${1}abc, as $1abc names the
+// group "1abc".
+type RegexRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// NewRegexRule compiles a regex rule.
+func NewRegexRule(pattern, replacement string) (*RegexRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return &RegexRule{re: re, replacement: replacement}, nil
+}
+
+// Apply rewrites path if the expression matches it.
+func (r *RegexRule) Apply(path string) (string, bool) {
+	if !r.re.MatchString(path) {
+		return path, false
+	}
+	return r.re.ReplaceAllString(path, r.replacement), true
+}
+
+// String describes the rule.
+func (r *RegexRule) String() string {
+	return fmt.Sprintf("regex %s => %s", r.re, r.replacement)
+}
+
+// GlobRule rewrites paths matching a glob to a template. In the glob, "*"
+// matches within a path segment, "**" across segments and "?" a single
+// character; the template references what they matched as {1}, {2}, ...
+type GlobRule struct {
+	pattern  string
+	template string
+	re       *regexp.Regexp
+}
+
+// NewGlobRule compiles a glob rule.
+func NewGlobRule(pattern, template string) (*GlobRule, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	groups := 0
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString("(.*)")
+			i++
+			groups++
+		case pattern[i] == '*':
+			expr.WriteString("([^/]*)")
+			groups++
+		case pattern[i] == '?':
+			expr.WriteString("([^/])")
+			groups++
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+
+	for _, m := range templateRef.FindAllStringSubmatch(template, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > groups {
+			return nil, fmt.Errorf("template %q references {%d} but glob %q has %d wildcards", template, n, pattern, groups)
+		}
+	}
+
+	return &GlobRule{pattern: pattern, template: template, re: regexp.MustCompile(expr.String())}, nil
+}
+
+// templateRef matches a wildcard reference in a glob template.
+var templateRef = regexp.MustCompile(`\{(\d+)\}`)
+
+// Apply rewrites path if the glob matches all of it.
+func (r *GlobRule) Apply(path string) (string, bool) {
+	m := r.re.FindStringSubmatch(path)
+	if m == nil {
+		return path, false
+	}
+	return templateRef.ReplaceAllStringFunc(r.template, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1 : len(ref)-1])
+		return m[n]
+	}), true
+}
+
+// String describes the rule.
+func (r *GlobRule) String() string {
+	return fmt.Sprintf("glob %s => %s", r.pattern, r.template)
+}
+
+// CaseRule converts paths to lower or upper case.
+type CaseRule struct {
+	upper bool
+}
+
+// NewCaseRule creates a case rule for "lower" or "upper".
+func NewCaseRule(mode string) (*CaseRule, error) {
+	switch mode {
+	case "lower":
+		return &CaseRule{}, nil
+	case "upper":
+		return &CaseRule{upper: true}, nil
+	default:
+		return nil, fmt.Errorf("invalid case %q (expected lower or upper)", mode)
+	}
+}
+
+// Apply converts path, firing if that changed it.
+func (r *CaseRule) Apply(path string) (string, bool) {
+	rewritten := strings.ToLower(path)
+	if r.upper {
+		rewritten = strings.ToUpper(path)
+	}
+	return rewritten, rewritten != path
+}
+
+// String describes the rule.
+func (r *CaseRule) String() string {
+	if r.upper {
+		return "case upper"
+	}
+	return "case lower"
+}
+
+// SanitizeRule replaces every run of characters other than letters, digits,
+// ".", "_", "-" and "/" with a replacement, and removes empty, "." and ".."
+// segments, leaving paths that are safe in OpenBao API URLs and policies.
+type SanitizeRule struct {
+	replacement string
+}
+
+// unsafeChars matches runs of characters a sanitize rule replaces.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// NewSanitizeRule creates a sanitize rule. The replacement must itself be
+// safe; it may be empty to drop unsafe characters.
+func NewSanitizeRule(replacement string) (*SanitizeRule, error) {
+	if unsafeChars.MatchString(replacement) || strings.Contains(replacement, "/") {
+		return nil, fmt.Errorf("invalid sanitize replacement %q", replacement)
+	}
+	return &SanitizeRule{replacement: replacement}, nil
+}
+
+// Apply sanitizes path, firing if that changed it.
+func (r *SanitizeRule) Apply(path string) (string, bool) {
+	rewritten := unsafeChars.ReplaceAllString(path, r.replacement)
+
+	segments := strings.Split(rewritten, "/")
+	kept := segments[:0]
+	for _, s := range segments {
+		if s != "" && s != "." && s != ".." {
+			kept = append(kept, s)
+		}
+	}
+	rewritten = strings.Join(kept, "/")
+
+	return rewritten, rewritten != path
+}
+
+// String describes the rule.
+func (r *SanitizeRule) String() string {
+	return fmt.Sprintf("sanitize => %q", r.replacement)
+}
+
+// Parse parses a rule spec as given on the command line:
+//
+//	regex:<pattern>=><replacement>
+//	glob:<pattern>=><template>
+//	case:lower | case:upper
+//	sanitize[:<replacement>]
+func Parse(spec string) (Rule, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case KindRegex, KindGlob:
+		pattern, replacement, ok := strings.Cut(arg, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid rewrite rule %q (expected '%s:<pattern>=><replacement>')", spec, kind)
+		}
+		if kind == KindRegex {
+			return NewRegexRule(pattern, replacement)
+		}
+		return NewGlobRule(pattern, replacement)
+	case KindCase:
+		return NewCaseRule(arg)
+	case KindSanitize:
+		if !strings.Contains(spec, ":") {
+			arg = DefaultSanitizeReplacement
+		}
+		return NewSanitizeRule(arg)
+	default:
+		return nil, fmt.Errorf("invalid rewrite rule %q (expected regex, glob, case or sanitize)", spec)
+	}
+}
+
+// ParseAll parses rule specs in order.
+func ParseAll(specs []string) (Rules, error) {
+	rules := make(Rules, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// file is the rules file format. Each rule sets exactly one kind:
+//
+//	rules:
+//	  - regex: '^prod/(.*)$'
+//	    replace: 'production/$1'
+//	  - glob: 'teams/*/db/**'
+//	    template: '{1}/database/{2}'
+//	  - case: lower
+//	  - sanitize: '_'
+type file struct {
+	Rules []fileRule `yaml:"rules"`
+}
+
+type fileRule struct {
+	Regex    string  `yaml:"regex"`
+	Replace  string  `yaml:"replace"`
+	Glob     string  `yaml:"glob"`
+	Template string  `yaml:"template"`
+	Case     string  `yaml:"case"`
+	Sanitize *string `yaml:"sanitize"`
+}
+
+// LoadFile reads rules from a YAML (or JSON) rules file.
+func LoadFile(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+
+	rules := make(Rules, 0, len(f.Rules))
+	for i, fr := range f.Rules {
+		rule, err := fr.rule()
+		if err != nil {
+			return nil, fmt.Errorf("rules file %s: rule %d: %w", path, i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// rule builds the rule described by a rules file entry.
+func (fr fileRule) rule() (Rule, error) {
+	kinds := 0
+	for _, set := range []bool{fr.Regex != "", fr.Glob != "", fr.Case != "", fr.Sanitize != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("expected exactly one of regex, glob, case or sanitize")
+	}
+
+	switch {
+	case fr.Regex != "":
+		return NewRegexRule(fr.Regex, fr.Replace)
+	case fr.Glob != "":
+		return NewGlobRule(fr.Glob, fr.Template)
+	case fr.Case != "":
+		return NewCaseRule(fr.Case)
+	default:
+		return NewSanitizeRule(*fr.Sanitize)
+	}
+}
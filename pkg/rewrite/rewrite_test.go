@@ -0,0 +1,306 @@
+package rewrite
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mustParseAll parses specs or fails the test.
+func mustParseAll(t *testing.T, specs ...string) Rules {
+	t.Helper()
+
+	rules, err := ParseAll(specs)
+	if err != nil {
+		t.Fatalf("ParseAll(%q) error = %v", specs, err)
+	}
+	return rules
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		path      string
+		want      string
+		wantFired int
+	}{
+		{
+			name:      "regex group reference",
+			specs:     []string{`regex:^prod/(.*)$=>production/$1`},
+			path:      "prod/app/db",
+			want:      "production/app/db",
+			wantFired: 1,
+		},
+		{
+			name:      "regex braced group reference",
+			specs:     []string{`regex:^prod_(.*)$=>${1}abc`},
+			path:      "prod_db",
+			want:      "dbabc",
+			wantFired: 1,
+		},
+		{
+			name:      "regex unbraced reference names another group",
+			specs:     []string{`regex:^prod_(.*)$=>x/$1abc`},
+			path:      "prod_db",
+			want:      "x/",
+			wantFired: 1,
+		},
+		{
+			name:      "regex named group",
+			specs:     []string{`regex:^(?P<env>[a-z]+)/(?P<rest>.*)$=>${rest}/${env}`},
+			path:      "prod/db",
+			want:      "db/prod",
+			wantFired: 1,
+		},
+		{
+			name:      "regex no match",
+			specs:     []string{`regex:^dev/=>development/`},
+			path:      "prod/db",
+			want:      "prod/db",
+			wantFired: 0,
+		},
+		{
+			name:      "glob single and double star",
+			specs:     []string{`glob:teams/*/db/**=>{1}/database/{2}`},
+			path:      "teams/core/db/main/user",
+			want:      "core/database/main/user",
+			wantFired: 1,
+		},
+		{
+			name:      "glob star stays within a segment",
+			specs:     []string{`glob:teams/*=>{1}`},
+			path:      "teams/core/db",
+			want:      "teams/core/db",
+			wantFired: 0,
+		},
+		{
+			name:      "glob question mark",
+			specs:     []string{`glob:v?/app=>app/v{1}`},
+			path:      "v2/app",
+			want:      "app/v2",
+			wantFired: 1,
+		},
+		{
+			name:      "case lower",
+			specs:     []string{"case:lower"},
+			path:      "Prod/DB",
+			want:      "prod/db",
+			wantFired: 1,
+		},
+		{
+			name:      "case upper unchanged does not fire",
+			specs:     []string{"case:upper"},
+			path:      "PROD/DB",
+			want:      "PROD/DB",
+			wantFired: 0,
+		},
+		{
+			name:      "sanitize default replacement",
+			specs:     []string{"sanitize"},
+			path:      "prod/my app//db:1/../x",
+			want:      "prod/my_app/db_1/x",
+			wantFired: 1,
+		},
+		{
+			name:      "sanitize custom replacement",
+			specs:     []string{"sanitize:-"},
+			path:      "a b",
+			want:      "a-b",
+			wantFired: 1,
+		},
+		{
+			name:      "sanitize empty replacement",
+			specs:     []string{"sanitize:"},
+			path:      "a b",
+			want:      "ab",
+			wantFired: 1,
+		},
+		{
+			name:      "rules apply in order",
+			specs:     []string{`regex:^Prod/=>production/`, "case:lower"},
+			path:      "Prod/DB",
+			want:      "production/db",
+			wantFired: 2,
+		},
+		{
+			name:      "later rule sees earlier output",
+			specs:     []string{"case:lower", `regex:^Prod/=>production/`},
+			path:      "Prod/DB",
+			want:      "prod/db",
+			wantFired: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mustParseAll(t, tt.specs...).Rewrite(tt.path)
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+			if result.Path != tt.want {
+				t.Errorf("Rewrite(%q).Path = %q, want %q", tt.path, result.Path, tt.want)
+			}
+			if len(result.Fired) != tt.wantFired {
+				t.Errorf("Rewrite(%q) fired %d rules, want %d", tt.path, len(result.Fired), tt.wantFired)
+			}
+		})
+	}
+}
+
+func TestRewriteEmptyPath(t *testing.T) {
+	_, err := mustParseAll(t, `regex:.*=>`).Rewrite("prod/db")
+	if err == nil || !strings.Contains(err.Error(), "empty path") {
+		t.Fatalf("Rewrite() error = %v, want an empty path error", err)
+	}
+}
+
+func TestRewriteAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		paths   []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "no rules",
+			paths: []string{"a", "b"},
+			want:  map[string]string{"a": "a", "b": "b"},
+		},
+		{
+			name:  "distinct destinations",
+			specs: []string{"case:lower"},
+			paths: []string{"A", "b"},
+			want:  map[string]string{"A": "a", "b": "b"},
+		},
+		{
+			name:    "collision",
+			specs:   []string{"case:lower"},
+			paths:   []string{"App", "app", "other"},
+			wantErr: "app <- App, app",
+		},
+		{
+			name:    "collision after sanitize",
+			specs:   []string{"sanitize"},
+			paths:   []string{"my app", "my:app"},
+			wantErr: "my_app <- my app, my:app",
+		},
+		{
+			name:    "empty destination",
+			specs:   []string{`regex:^tmp/.*$=>`},
+			paths:   []string{"tmp/x"},
+			wantErr: "empty path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := mustParseAll(t, tt.specs...).RewriteAll(tt.paths)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RewriteAll() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RewriteAll() error = %v", err)
+			}
+			got := make(map[string]string, len(results))
+			for path, result := range results {
+				got[path] = result.Path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RewriteAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "regex:^a", wantErr: "expected 'regex:<pattern>=><replacement>'"},
+		{spec: "regex:([=>x", wantErr: "invalid regex"},
+		{spec: "glob:a/*=>{2}", wantErr: "references {2}"},
+		{spec: "case:title", wantErr: "invalid case"},
+		{spec: "sanitize:/", wantErr: "invalid sanitize replacement"},
+		{spec: "sanitize: ", wantErr: "invalid sanitize replacement"},
+		{spec: "prefix:x", wantErr: "expected regex, glob, case or sanitize"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "all kinds in order",
+			content: `rules:
+  - regex: '^prod/(.*)$'
+    replace: 'production/${1}'
+  - glob: 'production/*/**'
+    template: '{1}/{2}'
+  - case: lower
+  - sanitize: '_'
+`,
+			path: "prod/Team A/db",
+			want: "team_a/db",
+		},
+		{
+			name:    "two kinds in one rule",
+			content: "rules:\n  - case: lower\n    sanitize: '_'\n",
+			wantErr: "rule 1: expected exactly one of",
+		},
+		{
+			name:    "no kind",
+			content: "rules:\n  - replace: x\n",
+			wantErr: "rule 1: expected exactly one of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			rules, err := LoadFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+
+			result, err := rules.Rewrite(tt.path)
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+			if result.Path != tt.want {
+				t.Errorf("Rewrite(%q).Path = %q, want %q", tt.path, result.Path, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// EncryptionMethodAge marks a payload encrypted to age recipients.
+	EncryptionMethodAge = "age"
+
+	// EncryptionMethodPassphrase marks a payload encrypted with an age
+	// scrypt passphrase.
+	EncryptionMethodPassphrase = "age-scrypt"
+)
+
+// EncryptionInfo describes how an export file payload was encrypted.
+// It never contains key material.
+type EncryptionInfo struct {
+	// Method is the encryption method (EncryptionMethodAge or EncryptionMethodPassphrase)
+	Method string `json:"method"`
+
+	// Recipients are the public age recipients the payload was encrypted to
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// Decrypter returns the age identities used to decrypt an encrypted export
+// file. It is only called for encrypted files, with the file's encryption info.
+type Decrypter func(info *EncryptionInfo) ([]age.Identity, error)
+
+// encryptedExportFile is the on-disk envelope of an encrypted export file.
+// Metadata is kept in cleartext so the file can be identified without keys;
+// Payload holds the complete armored, encrypted ExportFile.
+type encryptedExportFile struct {
+	Version  string         `json:"version"`
+	Metadata ExportMetadata `json:"metadata"`
+	Payload  string         `json:"payload"`
+}
+
+// WriteEncrypted writes the export file to the specified path, encrypting the
+// secrets to the given age recipients. info is recorded in the cleartext
+// metadata and inside the encrypted payload.
+func (e *ExportFile) WriteEncrypted(path string, recipients []age.Recipient, info EncryptionInfo) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	e.Metadata.Encryption = &info
+
+	plaintext, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal export file: %w", err)
+	}
+
+	var ciphertext bytes.Buffer
+	armorWriter := armor.NewWriter(&ciphertext)
+	w, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt export file: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return fmt.Errorf("failed to encrypt export file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt export file: %w", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt export file: %w", err)
+	}
+
+	envelope := encryptedExportFile{
+		Version:  e.Version,
+		Metadata: e.Metadata,
+		Payload:  ciphertext.String(),
+	}
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export file: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return nil
+}
+
+// decryptExportFile decrypts the payload of an encrypted export file.
+func decryptExportFile(envelope *encryptedExportFile, decrypt Decrypter) (*ExportFile, error) {
+	info := envelope.Metadata.Encryption
+	if info == nil {
+		return nil, fmt.Errorf("export file has a payload but no metadata.encryption")
+	}
+
+	if decrypt == nil {
+		return nil, fmt.Errorf("export file is encrypted (%s); no decryption keys provided", info.Method)
+	}
+
+	identities, err := decrypt(info)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("export file is encrypted (%s); no decryption keys provided", info.Method)
+	}
+
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(envelope.Payload)), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt export file: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt export file: %w", err)
+	}
+
+	var export ExportFile
+	if err := json.Unmarshal(plaintext, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted export file: %w", err)
+	}
+
+	// The cleartext metadata is not authenticated; it must match the copy
+	// inside the payload so it cannot be altered unnoticed
+	if err := checkEnvelope(envelope, &export); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// checkEnvelope returns an error if the cleartext version or metadata of
+// envelope differ from those of the decrypted export.
+func checkEnvelope(envelope *encryptedExportFile, export *ExportFile) error {
+	if envelope.Version != export.Version {
+		return fmt.Errorf("export file envelope version %q does not match the encrypted version %q", envelope.Version, export.Version)
+	}
+
+	cleartext, err := json.Marshal(envelope.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to compare export file metadata: %w", err)
+	}
+	encrypted, err := json.Marshal(export.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to compare export file metadata: %w", err)
+	}
+	if !bytes.Equal(cleartext, encrypted) {
+		return fmt.Errorf("export file metadata does not match the encrypted metadata; the file may have been tampered with")
+	}
+
+	return nil
+}
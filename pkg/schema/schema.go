@@ -44,6 +44,9 @@ type ExportMetadata struct {
 
 	// TotalSecrets is the count of secrets in the export
 	TotalSecrets int `json:"total_secrets"`
+
+	// Encryption describes how the secrets are encrypted (omitted for plaintext files)
+	Encryption *EncryptionInfo `json:"encryption,omitempty"`
 }
 
 // NewExportFile creates a new ExportFile with the current version.
@@ -78,13 +81,28 @@ func (e *ExportFile) Write(path string) error {
 	return nil
 }
 
-// ReadExportFile reads and parses an export file from the specified path.
+// ReadExportFile reads and parses a plaintext export file from the specified path.
 func ReadExportFile(path string) (*ExportFile, error) {
+	return ReadExportFileWith(path, nil)
+}
+
+// ReadExportFileWith reads and parses an export file from the specified path,
+// decrypting it with the identities returned by decrypt if it is encrypted.
+func ReadExportFileWith(path string, decrypt Decrypter) (*ExportFile, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read export file: %w", err)
 	}
 
+	var envelope encryptedExportFile
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse export file: %w", err)
+	}
+
+	if envelope.Payload != "" {
+		return decryptExportFile(&envelope, decrypt)
+	}
+
 	var export ExportFile
 	if err := json.Unmarshal(data, &export); err != nil {
 		return nil, fmt.Errorf("failed to parse export file: %w", err)
@@ -111,6 +129,12 @@ func (e *ExportFile) Validate() error {
 		return fmt.Errorf("missing required field: metadata.exported_at")
 	}
 
+	if enc := e.Metadata.Encryption; enc != nil {
+		if enc.Method != EncryptionMethodAge && enc.Method != EncryptionMethodPassphrase {
+			return fmt.Errorf("unsupported encryption method: %s", enc.Method)
+		}
+	}
+
 	for i, secret := range e.Secrets {
 		if secret.Path == "" {
 			return fmt.Errorf("secret at index %d: missing required field: path", i)
@@ -118,6 +142,11 @@ func (e *ExportFile) Validate() error {
 		if secret.Data == nil {
 			return fmt.Errorf("secret at index %d (%s): missing required field: data", i, secret.Path)
 		}
+		for j, version := range secret.Versions {
+			if version.Data == nil {
+				return fmt.Errorf("secret at index %d (%s): version at index %d: missing required field: data", i, secret.Path, j)
+			}
+		}
 	}
 
 	// Validate TotalSecrets matches actual count
@@ -129,9 +158,14 @@ func (e *ExportFile) Validate() error {
 	return nil
 }
 
-// ValidateFile reads and validates an export file.
+// ValidateFile reads and validates a plaintext export file.
 func ValidateFile(path string) (*ExportFile, error) {
-	export, err := ReadExportFile(path)
+	return ValidateFileWith(path, nil)
+}
+
+// ValidateFileWith reads, decrypts if needed, and validates an export file.
+func ValidateFileWith(path string, decrypt Decrypter) (*ExportFile, error) {
+	export, err := ReadExportFileWith(path, decrypt)
 	if err != nil {
 		return nil, err
 	}
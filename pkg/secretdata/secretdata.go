@@ -0,0 +1,19 @@
+// Package secretdata compares the key/value data of secrets.
+package secretdata
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Equal reports whether two secret values are the same once encoded as JSON.
+// Values read from OpenBao use json.Number while export files use float64,
+// so comparing the encoded form avoids false differences.
+func Equal(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(ja) == string(jb)
+}
@@ -0,0 +1,35 @@
+package secretdata
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{name: "same string", a: "v", b: "v", want: true},
+		{name: "different string", a: "v", b: "w", want: false},
+		{name: "json.Number and float64", a: json.Number("5432"), b: float64(5432), want: true},
+		{name: "json.Number and int", a: json.Number("1"), b: 1, want: true},
+		{name: "different numbers", a: json.Number("1"), b: float64(2), want: false},
+		{name: "number and numeric string", a: float64(1), b: "1", want: false},
+		{name: "nested maps in any key order", a: map[string]interface{}{"a": 1, "b": "x"}, b: map[string]interface{}{"b": "x", "a": json.Number("1")}, want: true},
+		{name: "nested maps differ", a: map[string]interface{}{"a": 1}, b: map[string]interface{}{"a": 2}, want: false},
+		{name: "lists", a: []interface{}{"a", float64(1)}, b: []interface{}{"a", json.Number("1")}, want: true},
+		{name: "list order matters", a: []interface{}{"a", "b"}, b: []interface{}{"b", "a"}, want: false},
+		{name: "nil and empty string", a: nil, b: "", want: false},
+		{name: "bool", a: true, b: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
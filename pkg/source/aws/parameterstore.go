@@ -0,0 +1,380 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+const (
+	// ParameterStoreSourceName is the identifier for the SSM Parameter Store source.
+	ParameterStoreSourceName = "aws-ssm-parameter-store"
+
+	// DefaultParameterPath is the parameter hierarchy read when none is configured.
+	DefaultParameterPath = "/"
+)
+
+func init() {
+	// Register this source with the default registry
+	source.Register(ParameterStoreSourceName, NewParameterStoreSource)
+}
+
+// ParameterStoreSource implements the source.Source interface for AWS Systems
+// Manager Parameter Store.
+//
+// Parameter names map to paths without the leading "/". In grouping mode,
+// sibling parameters are collapsed into one secret named after their parent
+// path, with one key per parameter: /prod/app/db_user and /prod/app/db_pass
+// become secret "prod/app" with keys "db_user" and "db_pass".
+type ParameterStoreSource struct {
+	client     *ssm.Client
+	region     string
+	rootPath   string // Parameter hierarchy to read (default: "/")
+	group      bool   // Collapse sibling parameters into one secret
+	nonJSONKey string // Key name for non-JSON parameters (default: "value")
+
+	retryer *retry.Retryer // Retries the page reads of List and Export; nil to disable
+}
+
+// NewParameterStoreSource creates a new AWS SSM Parameter Store source.
+func NewParameterStoreSource() source.Source {
+	return &ParameterStoreSource{
+		rootPath:   DefaultParameterPath,
+		nonJSONKey: DefaultNonJSONKey,
+	}
+}
+
+// Name returns the source identifier.
+func (s *ParameterStoreSource) Name() string {
+	return ParameterStoreSourceName
+}
+
+// Description returns a human-readable description.
+func (s *ParameterStoreSource) Description() string {
+	return "AWS Systems Manager Parameter Store"
+}
+
+// Configure initializes the source with AWS credentials and region.
+// Options:
+//   - region: AWS region (optional, falls back to AWS_REGION env var)
+//   - path: Parameter hierarchy to read recursively (default: "/")
+//   - group: Collapse sibling parameters into one multi-key secret (default: false)
+//   - non_json_key: Key name for non-JSON parameters when not grouping (default: "value")
+//
+// AWS credentials are loaded from the default credential chain, as for the
+// Secrets Manager source.
+func (s *ParameterStoreSource) Configure(ctx context.Context, opts map[string]interface{}) error {
+	if root := source.StringOption(opts, "path"); root != "" {
+		s.rootPath = "/" + strings.Trim(root, "/")
+	}
+
+	var err error
+	if s.group, err = source.BoolOption(opts, "group"); err != nil {
+		return err
+	}
+
+	// Get non-JSON key from options
+	if key, ok := opts["non_json_key"].(string); ok {
+		if key == "" {
+			return fmt.Errorf("non_json_key cannot be empty")
+		}
+		s.nonJSONKey = key
+	}
+
+	cfg, err := loadConfig(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	s.region = cfg.Region
+	s.client = ssm.NewFromConfig(cfg)
+	return nil
+}
+
+// List returns information about parameters (or parameter groups) matching
+// the given patterns without retrieving their values.
+func (s *ParameterStoreSource) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	// Create filter
+	pathFilter, err := filter.FromPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	input := &ssm.DescribeParametersInput{}
+	if s.rootPath != DefaultParameterPath {
+		input.ParameterFilters = []types.ParameterStringFilter{{
+			Key:    aws.String("Path"),
+			Option: aws.String("Recursive"),
+			Values: []string{s.rootPath},
+		}}
+	}
+
+	var secrets []source.SecretInfo
+	groups := make(map[string]int)
+	paginator := ssm.NewDescribeParametersPaginator(s.client, input)
+
+	for paginator.HasMorePages() {
+		var page *ssm.DescribeParametersOutput
+		err := s.retryer.Do(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list parameters: %w", err)
+		}
+
+		for _, param := range page.Parameters {
+			name := aws.ToString(param.Name)
+			if !strings.HasPrefix(name, "/") {
+				// Only hierarchical parameters are read by Get and Export
+				continue
+			}
+
+			if s.group {
+				groups[s.groupPath(name)]++
+				continue
+			}
+
+			p := parameterPath(name)
+
+			// Apply filter
+			if !pathFilter.Matches(p) {
+				continue
+			}
+
+			secrets = append(secrets, source.SecretInfo{
+				Path:        p,
+				Description: aws.ToString(param.Description),
+			})
+		}
+	}
+
+	for p, count := range groups {
+		// Apply filter
+		if !pathFilter.Matches(p) {
+			continue
+		}
+		secrets = append(secrets, source.SecretInfo{
+			Path:        p,
+			Description: fmt.Sprintf("%d parameters", count),
+		})
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Path < secrets[j].Path
+	})
+
+	return secrets, nil
+}
+
+// Get retrieves a single parameter, or in grouping mode all parameters
+// directly below the given path, with decryption.
+func (s *ParameterStoreSource) Get(ctx context.Context, p string) (*source.Secret, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	if s.group {
+		var params []types.Parameter
+		paginator := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+			Path:           aws.String("/" + p),
+			Recursive:      aws.Bool(false),
+			WithDecryption: aws.Bool(true),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parameters under %s: %w", p, err)
+			}
+			params = append(params, page.Parameters...)
+		}
+		if !strings.Contains(p, "/") {
+			// Top-level parameters are grouped under their own name
+			result, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+				Name:           aws.String("/" + p),
+				WithDecryption: aws.Bool(true),
+			})
+			if err == nil {
+				params = append(params, *result.Parameter)
+			}
+		}
+		if len(params) == 0 {
+			return nil, fmt.Errorf("failed to get parameters under %s: no parameters found", p)
+		}
+		return s.groupSecret(p, params), nil
+	}
+
+	result, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String("/" + p),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parameter %s: %w", p, err)
+	}
+
+	return s.parameterSecret(*result.Parameter), nil
+}
+
+// Export walks the parameter hierarchy recursively with GetParametersByPath,
+// decrypting SecureString values, and emits the secrets matching patterns.
+func (s *ParameterStoreSource) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	secretChan := make(chan *source.Secret)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(secretChan)
+		defer close(errChan)
+
+		if s.client == nil {
+			errChan <- fmt.Errorf("source not configured")
+			return
+		}
+
+		pathFilter, err := filter.FromPatterns(patterns)
+		if err != nil {
+			errChan <- fmt.Errorf("invalid pattern: %w", err)
+			return
+		}
+
+		var secrets []*source.Secret
+		groups := make(map[string][]types.Parameter)
+
+		paginator := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+			Path:           aws.String(s.rootPath),
+			Recursive:      aws.Bool(true),
+			WithDecryption: aws.Bool(true),
+		})
+		for paginator.HasMorePages() {
+			var page *ssm.GetParametersByPathOutput
+			err := s.retryer.Do(ctx, func() error {
+				var err error
+				page, err = paginator.NextPage(ctx)
+				return err
+			})
+			if err != nil {
+				errChan <- fmt.Errorf("failed to read parameters: %w", err)
+				return
+			}
+
+			for _, param := range page.Parameters {
+				name := aws.ToString(param.Name)
+				if s.group {
+					groups[s.groupPath(name)] = append(groups[s.groupPath(name)], param)
+					continue
+				}
+				if pathFilter.Matches(parameterPath(name)) {
+					secrets = append(secrets, s.parameterSecret(param))
+				}
+			}
+		}
+
+		for p, params := range groups {
+			if pathFilter.Matches(p) {
+				secrets = append(secrets, s.groupSecret(p, params))
+			}
+		}
+
+		for _, secret := range secrets {
+			select {
+			case secretChan <- secret:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return secretChan, errChan
+}
+
+// SetRetryer makes Export retry its page reads with retryer, implementing
+// source.BulkExporter. The SDK's own retries are turned off, so each call is
+// attempted once per retry and --max-retries and the rate limit apply as
+// set.
+func (s *ParameterStoreSource) SetRetryer(retryer *retry.Retryer) {
+	s.retryer = retryer
+	if s.client != nil {
+		s.client = ssm.New(s.client.Options(), func(o *ssm.Options) {
+			o.Retryer = aws.NopRetryer{}
+		})
+	}
+}
+
+// SetListRetryer makes List retry its page reads with retryer, implementing
+// source.PageRetrier.
+func (s *ParameterStoreSource) SetListRetryer(retryer *retry.Retryer) {
+	s.SetRetryer(retryer)
+}
+
+// Region returns the configured AWS region.
+func (s *ParameterStoreSource) Region() string {
+	return s.region
+}
+
+// parameterSecret converts a single parameter into a secret.
+func (s *ParameterStoreSource) parameterSecret(param types.Parameter) *source.Secret {
+	return &source.Secret{
+		Path: parameterPath(aws.ToString(param.Name)),
+		// JSON object: use parsed key-value pairs, otherwise use configured key
+		Data: source.StringData(aws.ToString(param.Value), s.nonJSONKey),
+		Metadata: source.SecretMetadata{
+			SourceID:  aws.ToString(param.ARN),
+			UpdatedAt: param.LastModifiedDate,
+		},
+	}
+}
+
+// groupSecret collapses sibling parameters into one secret keyed by the last
+// segment of each parameter name.
+func (s *ParameterStoreSource) groupSecret(p string, params []types.Parameter) *source.Secret {
+	secret := &source.Secret{
+		Path: p,
+		Data: make(map[string]interface{}, len(params)),
+	}
+
+	for _, param := range params {
+		name := aws.ToString(param.Name)
+		key := path.Base(name)
+		if parameterPath(name) == p {
+			// Top-level parameter without a parent
+			key = s.nonJSONKey
+		}
+		secret.Data[key] = aws.ToString(param.Value)
+
+		if modified := param.LastModifiedDate; modified != nil {
+			if secret.Metadata.UpdatedAt == nil || modified.After(*secret.Metadata.UpdatedAt) {
+				secret.Metadata.UpdatedAt = modified
+			}
+		}
+	}
+
+	return secret
+}
+
+// groupPath returns the secret path a parameter is grouped under: its parent
+// path, or its own path for top-level parameters.
+func (s *ParameterStoreSource) groupPath(name string) string {
+	parent := path.Dir(name)
+	if parent == "/" {
+		return parameterPath(name)
+	}
+	return parameterPath(parent)
+}
+
+// parameterPath converts a parameter name into a secret path.
+func parameterPath(name string) string {
+	return strings.TrimPrefix(name, "/")
+}
@@ -3,16 +3,17 @@ package aws
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"sync"
+	"slices"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 
 	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
 	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
 )
 
@@ -34,6 +35,8 @@ type Source struct {
 	client     *secretsmanager.Client
 	region     string
 	nonJSONKey string // Key name for non-JSON secrets (default: "value")
+
+	retryer *retry.Retryer // Retries the page reads of List; nil to disable
 }
 
 // NewSource creates a new AWS Secrets Manager source.
@@ -63,14 +66,6 @@ func (s *Source) Description() string {
 //   - Shared credentials file
 //   - IAM role (if running on EC2/ECS/Lambda)
 func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) error {
-	var cfgOpts []func(*config.LoadOptions) error
-
-	// Get region from options or environment
-	if region, ok := opts["region"].(string); ok && region != "" {
-		s.region = region
-		cfgOpts = append(cfgOpts, config.WithRegion(region))
-	}
-
 	// Get non-JSON key from options
 	if key, ok := opts["non_json_key"].(string); ok {
 		if key == "" {
@@ -79,21 +74,48 @@ func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) err
 		s.nonJSONKey = key
 	}
 
-	// Load AWS configuration using default credential chain
-	cfg, err := config.LoadDefaultConfig(ctx, cfgOpts...)
+	cfg, err := loadConfig(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
-	}
-
-	// Store the resolved region
-	if s.region == "" {
-		s.region = cfg.Region
+		return err
 	}
 
+	s.region = cfg.Region
 	s.client = secretsmanager.NewFromConfig(cfg)
 	return nil
 }
 
+// SetListRetryer makes List retry its page reads with retryer, implementing
+// source.PageRetrier. The SDK's own retries are turned off, so each call is
+// attempted once per retry and --max-retries and the rate limit apply as
+// set.
+func (s *Source) SetListRetryer(retryer *retry.Retryer) {
+	s.retryer = retryer
+	if s.client != nil {
+		s.client = secretsmanager.New(s.client.Options(), func(o *secretsmanager.Options) {
+			o.Retryer = aws.NopRetryer{}
+		})
+	}
+}
+
+// loadConfig loads the AWS configuration from the default credential chain,
+// applying the region option if set. It is shared by all AWS sources.
+func loadConfig(ctx context.Context, opts map[string]interface{}) (aws.Config, error) {
+	var cfgOpts []func(*config.LoadOptions) error
+
+	// Get region from options or environment
+	if region, ok := opts["region"].(string); ok && region != "" {
+		cfgOpts = append(cfgOpts, config.WithRegion(region))
+	}
+
+	// Load AWS configuration using default credential chain
+	cfg, err := config.LoadDefaultConfig(ctx, cfgOpts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return cfg, nil
+}
+
 // List returns information about secrets matching the given patterns.
 func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
 	if s.client == nil {
@@ -101,7 +123,7 @@ func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretIn
 	}
 
 	// Create filter
-	pathFilter, err := filter.NewPathFilter(patterns, nil)
+	pathFilter, err := filter.FromPatterns(patterns)
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
@@ -110,7 +132,12 @@ func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretIn
 	paginator := secretsmanager.NewListSecretsPaginator(s.client, &secretsmanager.ListSecretsInput{})
 
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
+		var page *secretsmanager.ListSecretsOutput
+		err := s.retryer.Do(ctx, func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list secrets: %w", err)
 		}
@@ -163,20 +190,10 @@ func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
 	// Handle binary vs string secrets
 	if result.SecretBinary != nil {
 		// Binary secret: base64 encode and use configured key
-		encoded := base64.StdEncoding.EncodeToString(result.SecretBinary)
-		secret.Data = map[string]interface{}{s.nonJSONKey: encoded}
+		secret.Data = source.BinaryData(result.SecretBinary, s.nonJSONKey)
 	} else if result.SecretString != nil {
-		secretString := aws.ToString(result.SecretString)
-
-		// Try to parse as JSON object
-		var data map[string]interface{}
-		if err := json.Unmarshal([]byte(secretString), &data); err != nil {
-			// Not JSON: use configured key
-			secret.Data = map[string]interface{}{s.nonJSONKey: secretString}
-		} else {
-			// Valid JSON object: use parsed key-value pairs
-			secret.Data = data
-		}
+		// JSON object: use parsed key-value pairs, otherwise use configured key
+		secret.Data = source.StringData(aws.ToString(result.SecretString), s.nonJSONKey)
 	}
 
 	// Get additional metadata
@@ -200,67 +217,63 @@ func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
 	return secret, nil
 }
 
-// Export retrieves all secrets matching the given patterns.
-func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
-	secretChan := make(chan *source.Secret)
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(secretChan)
-		defer close(errChan)
+// GetVersions retrieves every retained version of a secret other than
+// AWSCURRENT, oldest first, including versions without staging labels.
+func (s *Source) GetVersions(ctx context.Context, path string) ([]source.SecretVersion, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
 
-		// List all secrets matching patterns
-		infos, err := s.List(ctx, patterns)
+	var entries []types.SecretVersionsListEntry
+	paginator := secretsmanager.NewListSecretVersionIdsPaginator(s.client, &secretsmanager.ListSecretVersionIdsInput{
+		SecretId:          aws.String(path),
+		IncludeDeprecated: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
-			errChan <- err
-			return
+			return nil, fmt.Errorf("failed to list versions of secret %s: %w", path, err)
 		}
+		entries = append(entries, page.Versions...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return aws.ToTime(entries[i].CreatedDate).Before(aws.ToTime(entries[j].CreatedDate))
+	})
 
-		// Use a worker pool for fetching secrets
-		const workers = 5
-		var wg sync.WaitGroup
-		pathChan := make(chan string)
-
-		// Start workers
-		for i := 0; i < workers; i++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for path := range pathChan {
-					secret, err := s.Get(ctx, path)
-					if err != nil {
-						// Log error but continue
-						select {
-						case errChan <- err:
-						default:
-						}
-						continue
-					}
-					select {
-					case secretChan <- secret:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}()
+	var versions []source.SecretVersion
+	for _, entry := range entries {
+		if slices.Contains(entry.VersionStages, "AWSCURRENT") {
+			continue
 		}
 
-		// Send paths to workers
-		for _, info := range infos {
-			select {
-			case pathChan <- info.Path:
-			case <-ctx.Done():
-				close(pathChan)
-				return
-			}
+		result, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId:  aws.String(path),
+			VersionId: entry.VersionId,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version %s of secret %s: %w", aws.ToString(entry.VersionId), path, err)
 		}
-		close(pathChan)
 
-		// Wait for all workers to complete
-		wg.Wait()
-	}()
+		version := source.SecretVersion{
+			VersionID: aws.ToString(entry.VersionId),
+			Labels:    entry.VersionStages,
+			CreatedAt: entry.CreatedDate,
+		}
+		if result.SecretBinary != nil {
+			version.Data = source.BinaryData(result.SecretBinary, s.nonJSONKey)
+		} else {
+			version.Data = source.StringData(aws.ToString(result.SecretString), s.nonJSONKey)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
 
-	return secretChan, errChan
+// Export retrieves all secrets matching the given patterns.
+func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	return source.ExportConcurrently(ctx, s, patterns, source.DefaultExportWorkers)
 }
 
 // Region returns the configured AWS region.
@@ -0,0 +1,238 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testToken = "test-token"
+
+// fakeSecret is a secret served by newFakeVault.
+type fakeSecret struct {
+	value   string
+	enabled bool
+	tags    map[string]string
+}
+
+// newFakeVault starts a TLS stand-in of the Key Vault API serving secrets.
+// Requests without the test token get the bearer challenge the SDK expects.
+func newFakeVault(t *testing.T, secrets map[string]fakeSecret) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/secrets"), "/")
+		if name == "" {
+			var items []map[string]interface{}
+			for name, secret := range secrets {
+				items = append(items, map[string]interface{}{
+					"id":         srv.URL + "/secrets/" + name,
+					"attributes": map[string]interface{}{"enabled": secret.enabled},
+					"tags":       secret.tags,
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": items})
+			return
+		}
+
+		secret, ok := secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"code": "SecretNotFound", "message": "not found"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":         srv.URL + "/secrets/" + name + "/v1",
+			"value":      secret.value,
+			"attributes": map[string]interface{}{"enabled": secret.enabled},
+			"tags":       secret.tags,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestConfigureOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "missing vault_url",
+			opts:    map[string]interface{}{},
+			wantErr: "vault_url is required",
+		},
+		{
+			name:    "static token without endpoint",
+			opts:    map[string]interface{}{"vault_url": "https://myvault.vault.azure.net/", "static_token": testToken},
+			wantErr: "static_token requires an endpoint",
+		},
+		{
+			name:    "tls_skip_verify without endpoint",
+			opts:    map[string]interface{}{"vault_url": "https://myvault.vault.azure.net/", "tls_skip_verify": "true"},
+			wantErr: "tls_skip_verify requires an endpoint",
+		},
+		{
+			name: "endpoint with static token",
+			opts: map[string]interface{}{
+				"vault_url":       "https://myvault.vault.azure.net/",
+				"endpoint":        "https://127.0.0.1:8443/",
+				"static_token":    testToken,
+				"tls_skip_verify": "true",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSource().Configure(context.Background(), tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Configure() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Configure() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListAndGet(t *testing.T) {
+	srv := newFakeVault(t, map[string]fakeSecret{
+		"prod--app--db": {value: `{"user":"admin","password":"s3cret"}`, enabled: true, tags: map[string]string{"team": "core"}},
+		"prod--token":   {value: "plain", enabled: true},
+		"prod--old":     {value: "gone", enabled: false},
+	})
+
+	tests := []struct {
+		name      string
+		opts      map[string]interface{}
+		patterns  []string
+		wantPaths []string
+		wantData  map[string]map[string]interface{}
+	}{
+		{
+			name:      "enabled secrets",
+			wantPaths: []string{"prod/app/db", "prod/token"},
+			wantData: map[string]map[string]interface{}{
+				"prod/app/db": {"user": "admin", "password": "s3cret"},
+				"prod/token":  {"value": "plain"},
+			},
+		},
+		{
+			name:      "include disabled",
+			opts:      map[string]interface{}{"include_disabled": "true"},
+			wantPaths: []string{"prod/app/db", "prod/old", "prod/token"},
+		},
+		{
+			name:      "pattern",
+			patterns:  []string{"prod/app/*"},
+			wantPaths: []string{"prod/app/db"},
+		},
+		{
+			name:      "custom non-JSON key",
+			opts:      map[string]interface{}{"non_json_key": "secret"},
+			patterns:  []string{"prod/token"},
+			wantPaths: []string{"prod/token"},
+			wantData: map[string]map[string]interface{}{
+				"prod/token": {"secret": "plain"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := map[string]interface{}{
+				"vault_url":       "https://myvault.vault.azure.net/",
+				"endpoint":        srv.URL,
+				"static_token":    testToken,
+				"tls_skip_verify": "true",
+			}
+			for k, v := range tt.opts {
+				opts[k] = v
+			}
+
+			s := NewSource()
+			ctx := context.Background()
+			if err := s.Configure(ctx, opts); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			infos, err := s.List(ctx, tt.patterns)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			paths := make(map[string]bool)
+			for _, info := range infos {
+				paths[info.Path] = true
+			}
+			if len(paths) != len(tt.wantPaths) {
+				t.Fatalf("List() = %v, want %v", infos, tt.wantPaths)
+			}
+			for _, path := range tt.wantPaths {
+				if !paths[path] {
+					t.Fatalf("List() = %v, want %v", infos, tt.wantPaths)
+				}
+			}
+
+			for path, want := range tt.wantData {
+				secret, err := s.Get(ctx, path)
+				if err != nil {
+					t.Fatalf("Get(%q) error = %v", path, err)
+				}
+				if !reflect.DeepEqual(secret.Data, want) {
+					t.Errorf("Get(%q).Data = %v, want %v", path, secret.Data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	srv := newFakeVault(t, map[string]fakeSecret{
+		"app": {value: "v", enabled: true, tags: map[string]string{"team": "core"}},
+	})
+
+	s := NewSource()
+	ctx := context.Background()
+	if err := s.Configure(ctx, map[string]interface{}{
+		"vault_url":       "https://myvault.vault.azure.net/",
+		"endpoint":        srv.URL,
+		"static_token":    testToken,
+		"tls_skip_verify": "true",
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	secret, err := s.Get(ctx, "app")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := secret.Metadata.Tags["team"]; got != "core" {
+		t.Errorf("Tags[team] = %q, want %q", got, "core")
+	}
+	if secret.Metadata.Enabled == nil || !*secret.Metadata.Enabled {
+		t.Errorf("Enabled = %v, want true", secret.Metadata.Enabled)
+	}
+
+	if _, err := s.Get(ctx, "missing"); err == nil {
+		t.Error("Get(missing) error = nil, want an error")
+	}
+}
@@ -0,0 +1,34 @@
+package source
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// StringData converts a string secret value into secret data.
+// A JSON object is parsed into its key-value pairs; any other value is
+// stored under nonJSONKey.
+func StringData(value, nonJSONKey string) map[string]interface{} {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &data); err != nil || data == nil {
+		// Not a JSON object: use configured key
+		return map[string]interface{}{nonJSONKey: value}
+	}
+	return data
+}
+
+// BinaryData converts a binary secret value into secret data by base64
+// encoding it under nonJSONKey.
+func BinaryData(value []byte, nonJSONKey string) map[string]interface{} {
+	return map[string]interface{}{nonJSONKey: base64.StdEncoding.EncodeToString(value)}
+}
+
+// BytesData converts a secret payload of unknown type into secret data.
+// Valid UTF-8 payloads are handled by StringData, anything else by BinaryData.
+func BytesData(value []byte, nonJSONKey string) map[string]interface{} {
+	if utf8.Valid(value) {
+		return StringData(string(value), nonJSONKey)
+	}
+	return BinaryData(value, nonJSONKey)
+}
@@ -0,0 +1,78 @@
+package source
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultExportWorkers is the default number of concurrent Get calls used by
+// ExportConcurrently.
+const DefaultExportWorkers = 5
+
+// ExportConcurrently implements Source.Export on top of List and Get.
+// Secrets are fetched by a pool of workers; errors for individual secrets are
+// reported on the error channel without stopping the export.
+func ExportConcurrently(ctx context.Context, src Source, patterns []string, workers int) (<-chan *Secret, <-chan error) {
+	if workers <= 0 {
+		workers = DefaultExportWorkers
+	}
+
+	secretChan := make(chan *Secret)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(secretChan)
+		defer close(errChan)
+
+		// List all secrets matching patterns
+		infos, err := src.List(ctx, patterns)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		var wg sync.WaitGroup
+		pathChan := make(chan string)
+
+		// Start workers
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for path := range pathChan {
+					secret, err := src.Get(ctx, path)
+					if err != nil {
+						// Report error but continue
+						select {
+						case errChan <- err:
+						case <-ctx.Done():
+							return
+						}
+						continue
+					}
+					select {
+					case secretChan <- secret:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		// Send paths to workers
+	send:
+		for _, info := range infos {
+			select {
+			case pathChan <- info.Path:
+			case <-ctx.Done():
+				break send
+			}
+		}
+		close(pathChan)
+
+		// Wait for all workers to complete before closing the channels
+		wg.Wait()
+	}()
+
+	return secretChan, errChan
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "plain values",
+			content: "A=1\nB = two \n",
+			want:    map[string]interface{}{"A": "1", "B": "two"},
+		},
+		{
+			name:    "comments, blank lines and export",
+			content: "# comment\n\nexport A=1\n",
+			want:    map[string]interface{}{"A": "1"},
+		},
+		{
+			name:    "single quotes are literal",
+			content: `A='a\nb $x'` + "\n",
+			want:    map[string]interface{}{"A": `a\nb $x`},
+		},
+		{
+			name:    "double quotes with escapes",
+			content: `A="line\nnext\t\"q\" \\"` + "\n",
+			want:    map[string]interface{}{"A": "line\nnext\t\"q\" \\"},
+		},
+		{
+			name:    "empty value",
+			content: "A=\n",
+			want:    map[string]interface{}{"A": ""},
+		},
+		{
+			name:    "value containing equals",
+			content: "URL=postgres://u:p@h/db?sslmode=require\n",
+			want:    map[string]interface{}{"URL": "postgres://u:p@h/db?sslmode=require"},
+		},
+		{
+			name:    "missing equals",
+			content: "A\n",
+			wantErr: "line 1: expected KEY=value",
+		},
+		{
+			name:    "empty key",
+			content: "A=1\n=2\n",
+			wantErr: "line 2: empty key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv([]byte(tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDotenv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDotenv() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDotenv() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,571 @@
+// Package file provides sources that read secrets from local dotenv, YAML
+// and JSON files, optionally encrypted with SOPS.
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+const (
+	// SourceName is the identifier for this source.
+	SourceName = "file"
+
+	// DefaultNonJSONKey is the default key name for scalar values in key mode.
+	DefaultNonJSONKey = "value"
+
+	// Supported file formats.
+	FormatDotenv = "dotenv"
+	FormatYAML   = "yaml"
+	FormatJSON   = "json"
+
+	// PathModeFile derives one secret per file from the file path.
+	PathModeFile = "file"
+
+	// PathModeKey derives one secret per top-level key of each file.
+	PathModeKey = "key"
+)
+
+func init() {
+	// Register this source with the default registry
+	source.Register(SourceName, NewSource)
+}
+
+// skipFileError is returned by a decoder for a file it does not handle.
+// Such files are skipped when found while walking a directory and are an
+// error when given directly.
+type skipFileError struct {
+	reason string
+}
+
+func (e *skipFileError) Error() string {
+	return e.reason
+}
+
+// decoder turns the content of a file into a document.
+type decoder interface {
+	// configure reads decoder-specific options.
+	configure(opts map[string]interface{}) error
+
+	// supports reports whether files in the given format can be decoded.
+	supports(format string) bool
+
+	// decode parses content in the given format. The returned metadata is
+	// recorded on every secret read from the file; a zero UpdatedAt is
+	// replaced by the file modification time.
+	decode(ctx context.Context, path, format string, content []byte) (map[string]interface{}, source.SecretMetadata, error)
+}
+
+// plainDecoder decodes unencrypted files.
+type plainDecoder struct{}
+
+func (plainDecoder) configure(opts map[string]interface{}) error { return nil }
+
+func (plainDecoder) supports(format string) bool { return true }
+
+func (plainDecoder) decode(ctx context.Context, path, format string, content []byte) (map[string]interface{}, source.SecretMetadata, error) {
+	doc, err := parse(content, format)
+	return doc, source.SecretMetadata{}, err
+}
+
+// Source implements the source.Source interface for local files.
+type Source struct {
+	name        string
+	description string
+	decoder     decoder
+
+	paths      []string
+	format     string // Forced format; detected from the extension if empty
+	pathMode   string
+	rootKey    string // Dotted path of the subtree to read, e.g. "secrets"
+	nonJSONKey string
+
+	mu      sync.Mutex
+	secrets map[string]*source.Secret // Loaded lazily on first use
+}
+
+// NewSource creates a new file source.
+func NewSource() source.Source {
+	return &Source{
+		name:        SourceName,
+		description: "Local dotenv, YAML and JSON files",
+		decoder:     plainDecoder{},
+		pathMode:    PathModeFile,
+		nonJSONKey:  DefaultNonJSONKey,
+	}
+}
+
+// Name returns the source identifier.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Description returns a human-readable description.
+func (s *Source) Description() string {
+	return s.description
+}
+
+// Configure initializes the source with the files to read.
+// Options:
+//   - paths: Comma separated files or directories (required). Directories
+//     are walked recursively for .env, .yaml, .yml and .json files.
+//   - format: Force a format (dotenv, yaml, json) instead of detecting it
+//     from the file extension (optional)
+//   - path_mode: "file" (default) maps each file to one secret, using its
+//     path relative to the given directory without extension; "key" maps
+//     each top-level key to one secret
+//   - root_key: Dotted key of the subtree to read, e.g. "secrets" for a Helm
+//     values.yaml secrets block (optional)
+//   - non_json_key: Key name for scalar values in key mode (default: "value")
+func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) error {
+	s.paths = source.StringSliceOption(opts, "paths")
+	if len(s.paths) == 0 {
+		return fmt.Errorf("paths is required")
+	}
+
+	switch format := source.StringOption(opts, "format"); format {
+	case "", FormatDotenv, FormatYAML, FormatJSON:
+		if format != "" && !s.decoder.supports(format) {
+			return fmt.Errorf("format %s is not supported by the %s source", format, s.name)
+		}
+		s.format = format
+	default:
+		return fmt.Errorf("invalid format: %s (expected dotenv, yaml or json)", format)
+	}
+
+	switch mode := source.StringOption(opts, "path_mode"); mode {
+	case "":
+	case PathModeFile, PathModeKey:
+		s.pathMode = mode
+	default:
+		return fmt.Errorf("invalid path_mode: %s (expected file or key)", mode)
+	}
+
+	s.rootKey = source.StringOption(opts, "root_key")
+
+	// Get non-JSON key from options
+	if key, ok := opts["non_json_key"].(string); ok {
+		if key == "" {
+			return fmt.Errorf("non_json_key cannot be empty")
+		}
+		s.nonJSONKey = key
+	}
+
+	return s.decoder.configure(opts)
+}
+
+// List returns information about secrets matching the given patterns.
+func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
+	secrets, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create filter
+	pathFilter, err := filter.FromPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var infos []source.SecretInfo
+	for path := range secrets {
+		// Apply filter
+		if pathFilter.Matches(path) {
+			infos = append(infos, source.SecretInfo{Path: path})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Path < infos[j].Path
+	})
+
+	return infos, nil
+}
+
+// Get retrieves a single secret by path.
+func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
+	secrets, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, ok := secrets[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to get secret %s: not found", path)
+	}
+
+	// Callers such as transforms modify the secret; the cache must keep
+	// the values as read
+	return copySecret(secret), nil
+}
+
+// Export retrieves all secrets matching the given patterns.
+func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	return source.ExportConcurrently(ctx, s, patterns, source.DefaultExportWorkers)
+}
+
+// load reads all configured files once and indexes the secrets by path.
+func (s *Source) load(ctx context.Context) (map[string]*source.Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.secrets != nil {
+		return s.secrets, nil
+	}
+	if len(s.paths) == 0 {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	secrets := make(map[string]*source.Secret)
+	for _, root := range s.paths {
+		files, err := s.findFiles(root)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			fileSecrets, err := s.readFile(ctx, f.path, f.secretPath)
+			var skip *skipFileError
+			if errors.As(err, &skip) && f.walked {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, secret := range fileSecrets {
+				if existing, ok := secrets[secret.Path]; ok {
+					return nil, fmt.Errorf("duplicate secret path %s (from %s and %s)",
+						secret.Path, existing.Metadata.SourceID, secret.Metadata.SourceID)
+				}
+				secrets[secret.Path] = secret
+			}
+		}
+	}
+
+	s.secrets = secrets
+	return secrets, nil
+}
+
+// readFile parses a file into one secret (file mode) or one secret per
+// top-level key (key mode).
+func (s *Source) readFile(ctx context.Context, path, secretPath string) ([]*source.Secret, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	modTime := info.ModTime().UTC()
+
+	format := s.format
+	if format == "" {
+		format = detectFormat(path)
+	}
+
+	doc, meta, err := s.decoder.decode(ctx, path, format, content)
+	var skip *skipFileError
+	if errors.As(err, &skip) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if meta.UpdatedAt == nil {
+		meta.UpdatedAt = &modTime
+	}
+
+	if s.rootKey != "" {
+		if doc, err = subtree(doc, s.rootKey); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	if s.pathMode == PathModeFile {
+		meta.SourceID = absPath
+		return []*source.Secret{{
+			Path:     secretPath,
+			Data:     doc,
+			Metadata: meta,
+		}}, nil
+	}
+
+	secrets := make([]*source.Secret, 0, len(doc))
+	for key, value := range doc {
+		secret := &source.Secret{
+			Path:     key,
+			Metadata: meta,
+		}
+		secret.Metadata.SourceID = absPath + "#" + key
+		switch v := value.(type) {
+		case map[string]interface{}:
+			secret.Data = v
+		case string:
+			// JSON object: use parsed key-value pairs, otherwise use configured key
+			secret.Data = source.StringData(v, s.nonJSONKey)
+		default:
+			secret.Data = map[string]interface{}{s.nonJSONKey: v}
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+// sourceFile is a file to read and the secret path derived from it.
+type sourceFile struct {
+	path       string
+	secretPath string
+	walked     bool // Found while walking a directory rather than given directly
+}
+
+// findFiles returns the supported files at root. A file maps to its base
+// name; files in a directory map to their path relative to it. Extensions
+// are stripped from secret paths.
+func (s *Source) findFiles(root string) ([]sourceFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	if !info.IsDir() {
+		return []sourceFile{{path: root, secretPath: stripExt(filepath.Base(root))}}, nil
+	}
+
+	var files []sourceFile
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip hidden directories such as .git
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		format := detectFormat(path)
+		if format == "" {
+			return nil
+		}
+		if s.format != "" {
+			format = s.format
+		}
+		if !s.decoder.supports(format) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dir, base := filepath.Split(filepath.ToSlash(rel))
+		files = append(files, sourceFile{path: path, secretPath: dir + stripExt(base), walked: true})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// detectFormat returns the format for a file name, or "" if unsupported.
+func detectFormat(path string) string {
+	base := filepath.Base(path)
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".env":
+		return FormatDotenv
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	}
+	if strings.HasPrefix(base, ".env.") {
+		return FormatDotenv
+	}
+	return ""
+}
+
+// stripExt removes the extension from a file name. Dotfiles such as ".env"
+// keep their name without the leading dot.
+func stripExt(base string) string {
+	if strings.HasPrefix(base, ".env.") {
+		return strings.TrimPrefix(base, ".env.")
+	}
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	if stem == "" {
+		return strings.TrimPrefix(base, ".")
+	}
+	return stem
+}
+
+// parse decodes file content into a map.
+func parse(content []byte, format string) (map[string]interface{}, error) {
+	switch format {
+	case FormatDotenv:
+		return parseDotenv(content)
+	case FormatJSON:
+		var doc map[string]interface{}
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return nil, fmt.Errorf("document is not an object")
+		}
+		return doc, nil
+	case FormatYAML:
+		doc, err := parseYAML(content)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := normalize(doc).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("document is not a mapping")
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported file format")
+	}
+}
+
+// parseYAML decodes a single YAML document. Files with several documents
+// are rejected rather than reading only the first one.
+func parseYAML(content []byte) (interface{}, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+
+	var doc interface{}
+	count := 0
+	for {
+		var next interface{}
+		err := dec.Decode(&next)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// An empty document, e.g. after a trailing "---", holds no secrets
+		if next == nil {
+			continue
+		}
+		doc = next
+		count++
+	}
+
+	if count > 1 {
+		return nil, fmt.Errorf("file has %d YAML documents; only one is supported", count)
+	}
+	return doc, nil
+}
+
+// normalize converts YAML maps with non-string keys into
+// map[string]interface{} so the data can be encoded as JSON.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = normalize(val)
+		}
+		return t
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprintf("%v", k)] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range t {
+			t[i] = normalize(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// subtree returns the mapping at a dotted key path.
+func subtree(doc map[string]interface{}, dotted string) (map[string]interface{}, error) {
+	current := doc
+	for _, part := range strings.Split(dotted, ".") {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("root_key %s not found or not a mapping", dotted)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// copySecret returns a deep copy of secret.
+func copySecret(secret *source.Secret) *source.Secret {
+	c := *secret
+	c.Data = copyValue(secret.Data).(map[string]interface{})
+	if secret.Metadata.Tags != nil {
+		c.Metadata.Tags = make(map[string]string, len(secret.Metadata.Tags))
+		for k, v := range secret.Metadata.Tags {
+			c.Metadata.Tags[k] = v
+		}
+	}
+	c.Metadata.Enabled = copyPtr(secret.Metadata.Enabled)
+	c.Metadata.ExpiresAt = copyPtr(secret.Metadata.ExpiresAt)
+	c.Metadata.CreatedAt = copyPtr(secret.Metadata.CreatedAt)
+	c.Metadata.UpdatedAt = copyPtr(secret.Metadata.UpdatedAt)
+	return &c
+}
+
+// copyValue returns a deep copy of a decoded YAML or JSON value.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		c := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			c[k] = copyValue(elem)
+		}
+		return c
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		c := make([]interface{}, len(v))
+		for i, elem := range v {
+			c[i] = copyValue(elem)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// copyPtr returns a pointer to a copy of *p, or nil if p is nil.
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
@@ -0,0 +1,302 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files (relative path to content) below a temporary
+// directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// configure returns a file source reading paths (relative to dir) with opts.
+func configure(t *testing.T, dir string, paths []string, opts map[string]interface{}) *Source {
+	t.Helper()
+
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		abs[i] = filepath.Join(dir, filepath.FromSlash(p))
+	}
+	all := map[string]interface{}{"paths": strings.Join(abs, ",")}
+	for k, v := range opts {
+		all[k] = v
+	}
+
+	s := NewSource().(*Source)
+	if err := s.Configure(context.Background(), all); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	return s
+}
+
+func TestReadFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		paths    []string
+		opts     map[string]interface{}
+		wantData map[string]map[string]interface{}
+	}{
+		{
+			name:  "dotenv file",
+			files: map[string]string{"app.env": "USER=admin\nexport PASS='s3 cret'\n# comment\n"},
+			paths: []string{"app.env"},
+			wantData: map[string]map[string]interface{}{
+				"app": {"USER": "admin", "PASS": "s3 cret"},
+			},
+		},
+		{
+			name:  "yaml file",
+			files: map[string]string{"db.yaml": "user: admin\nport: 5432\nnested:\n  key: v\n"},
+			paths: []string{"db.yaml"},
+			wantData: map[string]map[string]interface{}{
+				"db": {"user": "admin", "port": 5432, "nested": map[string]interface{}{"key": "v"}},
+			},
+		},
+		{
+			name:  "json file",
+			files: map[string]string{"api.json": `{"token": "t", "count": 2}`},
+			paths: []string{"api.json"},
+			wantData: map[string]map[string]interface{}{
+				"api": {"token": "t", "count": float64(2)},
+			},
+		},
+		{
+			name: "directory in file mode",
+			files: map[string]string{
+				"prod/db.yaml":  "user: admin\n",
+				"prod/.env.web": "KEY=v\n",
+				"README.md":     "not a secret",
+				".git/x.json":   `{"hidden": true}`,
+			},
+			paths: []string{"."},
+			wantData: map[string]map[string]interface{}{
+				"prod/db":  {"user": "admin"},
+				"prod/web": {"KEY": "v"},
+			},
+		},
+		{
+			name:  "key mode",
+			files: map[string]string{"all.yaml": "db:\n  user: admin\ntoken: plain\njson: '{\"a\":\"b\"}'\nport: 1\n"},
+			paths: []string{"all.yaml"},
+			opts:  map[string]interface{}{"path_mode": "key", "non_json_key": "v"},
+			wantData: map[string]map[string]interface{}{
+				"db":    {"user": "admin"},
+				"token": {"v": "plain"},
+				"json":  {"a": "b"},
+				"port":  {"v": 1},
+			},
+		},
+		{
+			name:  "root key",
+			files: map[string]string{"values.yaml": "image: app\nsecrets:\n  db:\n    password: p\n  api:\n    token: t\n"},
+			paths: []string{"values.yaml"},
+			opts:  map[string]interface{}{"path_mode": "key", "root_key": "secrets"},
+			wantData: map[string]map[string]interface{}{
+				"db":  {"password": "p"},
+				"api": {"token": "t"},
+			},
+		},
+		{
+			name:  "forced format",
+			files: map[string]string{"config.txt": "A=1\n"},
+			paths: []string{"config.txt"},
+			opts:  map[string]interface{}{"format": "dotenv"},
+			wantData: map[string]map[string]interface{}{
+				"config": {"A": "1"},
+			},
+		},
+		{
+			name:  "trailing document separator",
+			files: map[string]string{"db.yaml": "user: admin\n---\n"},
+			paths: []string{"db.yaml"},
+			wantData: map[string]map[string]interface{}{
+				"db": {"user": "admin"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			s := configure(t, dir, tt.paths, tt.opts)
+			ctx := context.Background()
+
+			infos, err := s.List(ctx, nil)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var paths, wantPaths []string
+			for _, info := range infos {
+				paths = append(paths, info.Path)
+			}
+			for path := range tt.wantData {
+				wantPaths = append(wantPaths, path)
+			}
+			sort.Strings(wantPaths)
+			if !reflect.DeepEqual(paths, wantPaths) {
+				t.Fatalf("List() paths = %v, want %v", paths, wantPaths)
+			}
+
+			for path, want := range tt.wantData {
+				secret, err := s.Get(ctx, path)
+				if err != nil {
+					t.Fatalf("Get(%q) error = %v", path, err)
+				}
+				if !reflect.DeepEqual(secret.Data, want) {
+					t.Errorf("Get(%q).Data = %#v, want %#v", path, secret.Data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		paths   []string
+		opts    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "duplicate path across files",
+			files:   map[string]string{"a.yaml": "db: x\n", "b.yaml": "db: y\n"},
+			paths:   []string{"a.yaml", "b.yaml"},
+			opts:    map[string]interface{}{"path_mode": "key"},
+			wantErr: "duplicate secret path db",
+		},
+		{
+			name:    "duplicate path across formats",
+			files:   map[string]string{"app.yaml": "a: 1\n", "app.json": `{"a": 1}`},
+			paths:   []string{"."},
+			wantErr: "duplicate secret path app",
+		},
+		{
+			name:    "multiple YAML documents",
+			files:   map[string]string{"multi.yaml": "a: 1\n---\nb: 2\n"},
+			paths:   []string{"multi.yaml"},
+			wantErr: "file has 2 YAML documents",
+		},
+		{
+			name:    "YAML list",
+			files:   map[string]string{"list.yaml": "- a\n- b\n"},
+			paths:   []string{"list.yaml"},
+			wantErr: "document is not a mapping",
+		},
+		{
+			name:    "JSON array",
+			files:   map[string]string{"list.json": `["a"]`},
+			paths:   []string{"list.json"},
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "invalid dotenv line",
+			files:   map[string]string{"bad.env": "A=1\nnot a pair\n"},
+			paths:   []string{"bad.env"},
+			wantErr: "line 2: expected KEY=value",
+		},
+		{
+			name:    "missing root key",
+			files:   map[string]string{"values.yaml": "image: app\n"},
+			paths:   []string{"values.yaml"},
+			opts:    map[string]interface{}{"root_key": "secrets"},
+			wantErr: "secrets",
+		},
+		{
+			name:    "missing file",
+			paths:   []string{"missing.yaml"},
+			wantErr: "failed to read",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			s := configure(t, dir, tt.paths, tt.opts)
+
+			_, err := s.List(context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("List() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigureOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]interface{}
+		wantErr string
+	}{
+		{name: "missing paths", opts: map[string]interface{}{}, wantErr: "paths is required"},
+		{name: "invalid format", opts: map[string]interface{}{"paths": "x", "format": "toml"}, wantErr: "invalid format"},
+		{name: "invalid path mode", opts: map[string]interface{}{"paths": "x", "path_mode": "dir"}, wantErr: "invalid path_mode"},
+		{name: "empty non_json_key", opts: map[string]interface{}{"paths": "x", "non_json_key": ""}, wantErr: "non_json_key cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSource().Configure(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Configure() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetReturnsCopies(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db.yaml": "user: admin\nnested:\n  key: v\nlist:\n  - a\n",
+	})
+	s := configure(t, dir, []string{"db.yaml"}, nil)
+	ctx := context.Background()
+
+	first, err := s.Get(ctx, "db")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	first.Path = "changed"
+	first.Data["user"] = "changed"
+	first.Data["nested"].(map[string]interface{})["key"] = "changed"
+	first.Data["list"].([]interface{})[0] = "changed"
+	*first.Metadata.UpdatedAt = first.Metadata.UpdatedAt.AddDate(-1, 0, 0)
+
+	second, err := s.Get(ctx, "db")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"user":   "admin",
+		"nested": map[string]interface{}{"key": "v"},
+		"list":   []interface{}{"a"},
+	}
+	if second.Path != "db" {
+		t.Errorf("Path = %q, want %q", second.Path, "db")
+	}
+	if !reflect.DeepEqual(second.Data, want) {
+		t.Errorf("Data = %v, want %v", second.Data, want)
+	}
+	if second.Metadata.UpdatedAt.Equal(*first.Metadata.UpdatedAt) {
+		t.Error("UpdatedAt is shared between Get calls")
+	}
+}
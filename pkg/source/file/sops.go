@@ -0,0 +1,176 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+const (
+	// SOPSSourceName is the identifier for the SOPS source.
+	SOPSSourceName = "sops"
+
+	// DefaultSOPSBinary is the sops executable used for decryption.
+	DefaultSOPSBinary = "sops"
+)
+
+func init() {
+	// Register this source with the default registry
+	source.Register(SOPSSourceName, NewSOPSSource)
+}
+
+// NewSOPSSource creates a new source for SOPS-encrypted YAML and JSON files.
+// Files are decrypted with the sops binary, so every key type and key
+// location sops supports (age, PGP, cloud KMS) works as it does on the
+// command line.
+func NewSOPSSource() source.Source {
+	return &Source{
+		name:        SOPSSourceName,
+		description: "SOPS-encrypted YAML and JSON files",
+		decoder:     &sopsDecoder{binary: DefaultSOPSBinary},
+		pathMode:    PathModeFile,
+		nonJSONKey:  DefaultNonJSONKey,
+	}
+}
+
+// sopsDecoder decrypts SOPS-encrypted files.
+type sopsDecoder struct {
+	binary string
+}
+
+// configure reads the SOPS options.
+// Options:
+//   - sops_binary: Path to the sops executable (default: "sops")
+func (d *sopsDecoder) configure(opts map[string]interface{}) error {
+	if binary := source.StringOption(opts, "sops_binary"); binary != "" {
+		d.binary = binary
+	}
+	if _, err := exec.LookPath(d.binary); err != nil {
+		return fmt.Errorf("sops binary not found: %w", err)
+	}
+	return nil
+}
+
+// supports reports whether the format can hold SOPS metadata.
+func (d *sopsDecoder) supports(format string) bool {
+	return format == FormatYAML || format == FormatJSON
+}
+
+// decode decrypts content with sops. Files without a top-level "sops" block
+// are not encrypted and report a skipFileError.
+func (d *sopsDecoder) decode(ctx context.Context, path, format string, content []byte) (map[string]interface{}, source.SecretMetadata, error) {
+	if !d.supports(format) {
+		return nil, source.SecretMetadata{}, fmt.Errorf("unsupported file format for sops: %s", format)
+	}
+
+	encrypted, err := parse(content, format)
+	if err != nil {
+		return nil, source.SecretMetadata{}, err
+	}
+	sopsBlock, ok := encrypted["sops"].(map[string]interface{})
+	if !ok {
+		return nil, source.SecretMetadata{}, &skipFileError{reason: "not SOPS-encrypted"}
+	}
+
+	// Let sops pick up keys from its usual locations (SOPS_AGE_KEY_FILE,
+	// the GnuPG agent, cloud credentials) via the inherited environment
+	cmd := exec.CommandContext(ctx, d.binary, "--decrypt",
+		"--input-type", format, "--output-type", FormatJSON, path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, source.SecretMetadata{}, fmt.Errorf("sops decrypt failed: %s", msg)
+		}
+		return nil, source.SecretMetadata{}, fmt.Errorf("sops decrypt failed: %w", err)
+	}
+
+	doc, err := parse(stdout.Bytes(), FormatJSON)
+	if err != nil {
+		return nil, source.SecretMetadata{}, fmt.Errorf("failed to parse decrypted output: %w", err)
+	}
+
+	return doc, sopsMetadata(sopsBlock), nil
+}
+
+// sopsRecipientFields maps SOPS key types to the field identifying each key.
+var sopsRecipientFields = map[string]string{
+	"age":      "recipient",
+	"pgp":      "fp",
+	"kms":      "arn",
+	"gcp_kms":  "resource_id",
+	"azure_kv": "vault_url",
+	"hc_vault": "vault_address",
+}
+
+// sopsMetadata converts the "sops" block of an encrypted file into secret
+// metadata. Recipients are recorded as "sops_<type>" tags with comma
+// separated values, the SOPS version as "sops_version" and the last
+// modification time as UpdatedAt.
+func sopsMetadata(block map[string]interface{}) source.SecretMetadata {
+	recipients := make(map[string][]string)
+	collectSOPSRecipients(block, recipients)
+
+	// Shamir key groups nest the same key lists one level down
+	if groups, ok := block["key_groups"].([]interface{}); ok {
+		for _, g := range groups {
+			if group, ok := g.(map[string]interface{}); ok {
+				collectSOPSRecipients(group, recipients)
+			}
+		}
+	}
+
+	tags := make(map[string]string)
+	for keyType, ids := range recipients {
+		sort.Strings(ids)
+		tags["sops_"+keyType] = strings.Join(ids, ",")
+	}
+	if version := source.StringOption(block, "version"); version != "" {
+		tags["sops_version"] = version
+	}
+
+	var meta source.SecretMetadata
+	if len(tags) > 0 {
+		meta.Tags = tags
+	}
+	switch v := block["lastmodified"].(type) {
+	case time.Time:
+		// Unquoted YAML timestamps are decoded as time.Time
+		modified := v.UTC()
+		meta.UpdatedAt = &modified
+	case string:
+		if modified, err := time.Parse(time.RFC3339, v); err == nil {
+			modified = modified.UTC()
+			meta.UpdatedAt = &modified
+		}
+	}
+
+	return meta
+}
+
+// collectSOPSRecipients appends the key identifiers listed in m to
+// recipients, keyed by key type.
+func collectSOPSRecipients(m map[string]interface{}, recipients map[string][]string) {
+	for keyType, field := range sopsRecipientFields {
+		entries, ok := m[keyType].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, e := range entries {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if id := source.StringOption(entry, field); id != "" {
+				recipients[keyType] = append(recipients[keyType], id)
+			}
+		}
+	}
+}
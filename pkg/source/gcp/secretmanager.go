@@ -0,0 +1,241 @@
+// Package gcp provides the Google Cloud Secret Manager source implementation.
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+const (
+	// SourceName is the identifier for this source.
+	SourceName = "gcp-secret-manager"
+
+	// DefaultNonJSONKey is the default key name for non-JSON secrets.
+	DefaultNonJSONKey = "value"
+
+	// DefaultVersion is the secret version resolved when none is pinned.
+	DefaultVersion = "latest"
+)
+
+func init() {
+	// Register this source with the default registry
+	source.Register(SourceName, NewSource)
+}
+
+// Source implements the source.Source interface for GCP Secret Manager.
+type Source struct {
+	client     *secretmanager.Client
+	project    string
+	version    string // Version to resolve for every secret (default: "latest")
+	nonJSONKey string // Key name for non-JSON secrets (default: "value")
+}
+
+// NewSource creates a new GCP Secret Manager source.
+func NewSource() source.Source {
+	return &Source{
+		version:    DefaultVersion,
+		nonJSONKey: DefaultNonJSONKey,
+	}
+}
+
+// Name returns the source identifier.
+func (s *Source) Name() string {
+	return SourceName
+}
+
+// Description returns a human-readable description.
+func (s *Source) Description() string {
+	return "Google Cloud Secret Manager"
+}
+
+// Configure initializes the source with GCP credentials and project.
+// Options:
+//   - project: GCP project ID (falls back to GOOGLE_CLOUD_PROJECT env var)
+//   - version: Secret version to read, "latest" or a version number (default: "latest")
+//   - non_json_key: Key name for non-JSON secrets (default: "value")
+//   - credentials_file: Service account key file (optional)
+//   - endpoint: Secret Manager API endpoint override (optional)
+//   - insecure: Connect to endpoint without TLS or authentication, for local fakes (optional)
+//
+// Credentials are otherwise loaded from Application Default Credentials.
+func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) error {
+	s.project = source.StringOption(opts, "project")
+	if s.project == "" {
+		s.project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if s.project == "" {
+		return fmt.Errorf("project is required (set the project option or GOOGLE_CLOUD_PROJECT)")
+	}
+
+	if version := source.StringOption(opts, "version"); version != "" {
+		s.version = version
+	}
+
+	// Get non-JSON key from options
+	if key, ok := opts["non_json_key"].(string); ok {
+		if key == "" {
+			return fmt.Errorf("non_json_key cannot be empty")
+		}
+		s.nonJSONKey = key
+	}
+
+	useInsecure, err := source.BoolOption(opts, "insecure")
+	if err != nil {
+		return err
+	}
+
+	var clientOpts []option.ClientOption
+	if endpoint := source.StringOption(opts, "endpoint"); endpoint != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(endpoint))
+	} else if useInsecure {
+		return fmt.Errorf("insecure requires an endpoint")
+	}
+	if useInsecure {
+		clientOpts = append(clientOpts,
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+	} else if file := source.StringOption(opts, "credentials_file"); file != "" {
+		clientOpts = append(clientOpts, option.WithCredentialsFile(file))
+	}
+
+	client, err := secretmanager.NewClient(ctx, clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create GCP Secret Manager client: %w", err)
+	}
+
+	s.client = client
+	return nil
+}
+
+// List returns information about secrets matching the given patterns.
+func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	// Create filter
+	pathFilter, err := filter.FromPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var secrets []source.SecretInfo
+	it := s.client.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{
+		Parent: "projects/" + s.project,
+	})
+
+	for {
+		secret, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		path := secretID(secret.GetName())
+
+		// Apply filter
+		if !pathFilter.Matches(path) {
+			continue
+		}
+
+		secrets = append(secrets, source.SecretInfo{
+			Path: path,
+			Tags: copyLabels(secret.GetLabels()),
+		})
+	}
+
+	return secrets, nil
+}
+
+// Get retrieves a single secret by path, resolving the configured version.
+func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	secretName := fmt.Sprintf("projects/%s/secrets/%s", s.project, path)
+
+	result, err := s.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: fmt.Sprintf("%s/versions/%s", secretName, s.version),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", path, err)
+	}
+
+	payload := result.GetPayload()
+	if payload.DataCrc32C != nil {
+		checksum := crc32.Checksum(payload.GetData(), crc32.MakeTable(crc32.Castagnoli))
+		if int64(checksum) != payload.GetDataCrc32C() {
+			return nil, fmt.Errorf("failed to get secret %s: payload checksum mismatch", path)
+		}
+	}
+
+	secret := &source.Secret{
+		Path: path,
+		// Binary payloads are base64 encoded, text is handled like AWS secret strings
+		Data: source.BytesData(payload.GetData(), s.nonJSONKey),
+		Metadata: source.SecretMetadata{
+			// The resolved version name, e.g. projects/p/secrets/db/versions/3
+			SourceID: result.GetName(),
+		},
+	}
+
+	// Get additional metadata
+	meta, err := s.client.GetSecret(ctx, &secretmanagerpb.GetSecretRequest{Name: secretName})
+	if err == nil {
+		secret.Metadata.Tags = copyLabels(meta.GetLabels())
+		if meta.GetCreateTime() != nil {
+			createdAt := meta.GetCreateTime().AsTime()
+			secret.Metadata.CreatedAt = &createdAt
+		}
+	}
+
+	version, err := s.client.GetSecretVersion(ctx, &secretmanagerpb.GetSecretVersionRequest{Name: result.GetName()})
+	if err == nil && version.GetCreateTime() != nil {
+		updatedAt := version.GetCreateTime().AsTime()
+		secret.Metadata.UpdatedAt = &updatedAt
+	}
+
+	return secret, nil
+}
+
+// Export retrieves all secrets matching the given patterns.
+func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	return source.ExportConcurrently(ctx, s, patterns, source.DefaultExportWorkers)
+}
+
+// Project returns the configured GCP project.
+func (s *Source) Project() string {
+	return s.project
+}
+
+// secretID extracts the secret ID from a resource name of the form
+// projects/<project>/secrets/<id>.
+func secretID(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	tags := make(map[string]string, len(labels))
+	for k, v := range labels {
+		tags[k] = v
+	}
+	return tags
+}
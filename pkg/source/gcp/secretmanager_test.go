@@ -0,0 +1,264 @@
+package gcp
+
+import (
+	"context"
+	"hash/crc32"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeSecretManager serves secret payloads by resource name, e.g.
+// projects/p/secrets/db/versions/latest.
+type fakeSecretManager struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+
+	labels   map[string]map[string]string // Secret ID to labels
+	payloads map[string][]byte            // Version name to payload
+	badCRC   bool                         // Report a wrong payload checksum
+}
+
+func (f *fakeSecretManager) ListSecrets(ctx context.Context, req *secretmanagerpb.ListSecretsRequest) (*secretmanagerpb.ListSecretsResponse, error) {
+	resp := &secretmanagerpb.ListSecretsResponse{}
+	for id, labels := range f.labels {
+		resp.Secrets = append(resp.Secrets, &secretmanagerpb.Secret{
+			Name:   req.GetParent() + "/secrets/" + id,
+			Labels: labels,
+		})
+	}
+	return resp, nil
+}
+
+func (f *fakeSecretManager) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	data, ok := f.payloads[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s not found", req.GetName())
+	}
+	checksum := int64(crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+	if f.badCRC {
+		checksum++
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    req.GetName(),
+		Payload: &secretmanagerpb.SecretPayload{Data: data, DataCrc32C: proto.Int64(checksum)},
+	}, nil
+}
+
+func (f *fakeSecretManager) GetSecret(ctx context.Context, req *secretmanagerpb.GetSecretRequest) (*secretmanagerpb.Secret, error) {
+	labels, ok := f.labels[secretID(req.GetName())]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s not found", req.GetName())
+	}
+	return &secretmanagerpb.Secret{Name: req.GetName(), Labels: labels}, nil
+}
+
+// newFakeServer starts fake on a local port and returns its address.
+func newFakeServer(t *testing.T, fake *fakeSecretManager) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(srv, fake)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// configure returns a source connected to the fake server at endpoint.
+func configure(t *testing.T, endpoint string, opts map[string]interface{}) *Source {
+	t.Helper()
+
+	all := map[string]interface{}{
+		"project":  "p",
+		"endpoint": endpoint,
+		"insecure": "true",
+	}
+	for k, v := range opts {
+		all[k] = v
+	}
+
+	s := NewSource().(*Source)
+	if err := s.Configure(context.Background(), all); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() { s.client.Close() })
+	return s
+}
+
+func TestConfigureOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "insecure without endpoint",
+			opts:    map[string]interface{}{"project": "p", "insecure": "true"},
+			wantErr: "insecure requires an endpoint",
+		},
+		{
+			name:    "empty non_json_key",
+			opts:    map[string]interface{}{"project": "p", "non_json_key": ""},
+			wantErr: "non_json_key cannot be empty",
+		},
+		{
+			name:    "invalid insecure",
+			opts:    map[string]interface{}{"project": "p", "insecure": "maybe"},
+			wantErr: "invalid value for insecure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSource().Configure(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Configure() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListAndGet(t *testing.T) {
+	fake := &fakeSecretManager{
+		labels: map[string]map[string]string{
+			"db":    {"team": "core"},
+			"token": nil,
+		},
+		payloads: map[string][]byte{
+			"projects/p/secrets/db/versions/latest":    []byte(`{"user":"admin","password":"s3cret"}`),
+			"projects/p/secrets/token/versions/latest": []byte("plain"),
+			"projects/p/secrets/token/versions/2":      []byte("older"),
+		},
+	}
+	endpoint := newFakeServer(t, fake)
+
+	tests := []struct {
+		name      string
+		opts      map[string]interface{}
+		patterns  []string
+		wantPaths []string
+		wantData  map[string]map[string]interface{}
+	}{
+		{
+			name:      "latest versions",
+			wantPaths: []string{"db", "token"},
+			wantData: map[string]map[string]interface{}{
+				"db":    {"user": "admin", "password": "s3cret"},
+				"token": {"value": "plain"},
+			},
+		},
+		{
+			name:      "pattern",
+			patterns:  []string{"d*"},
+			wantPaths: []string{"db"},
+		},
+		{
+			name:      "pinned version and custom non-JSON key",
+			opts:      map[string]interface{}{"version": "2", "non_json_key": "secret"},
+			patterns:  []string{"token"},
+			wantPaths: []string{"token"},
+			wantData: map[string]map[string]interface{}{
+				"token": {"secret": "older"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := configure(t, endpoint, tt.opts)
+			ctx := context.Background()
+
+			infos, err := s.List(ctx, tt.patterns)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			paths := make(map[string]bool)
+			for _, info := range infos {
+				paths[info.Path] = true
+			}
+			if len(paths) != len(tt.wantPaths) {
+				t.Fatalf("List() = %v, want %v", infos, tt.wantPaths)
+			}
+			for _, path := range tt.wantPaths {
+				if !paths[path] {
+					t.Fatalf("List() = %v, want %v", infos, tt.wantPaths)
+				}
+			}
+
+			for path, want := range tt.wantData {
+				secret, err := s.Get(ctx, path)
+				if err != nil {
+					t.Fatalf("Get(%q) error = %v", path, err)
+				}
+				if !reflect.DeepEqual(secret.Data, want) {
+					t.Errorf("Get(%q).Data = %v, want %v", path, secret.Data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fake    *fakeSecretManager
+		path    string
+		wantErr string
+	}{
+		{
+			name:    "missing secret",
+			fake:    &fakeSecretManager{},
+			path:    "missing",
+			wantErr: "failed to get secret missing",
+		},
+		{
+			name: "checksum mismatch",
+			fake: &fakeSecretManager{
+				payloads: map[string][]byte{"projects/p/secrets/db/versions/latest": []byte("x")},
+				badCRC:   true,
+			},
+			path:    "db",
+			wantErr: "payload checksum mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := configure(t, newFakeServer(t, tt.fake), nil)
+			_, err := s.Get(context.Background(), tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetLabels(t *testing.T) {
+	fake := &fakeSecretManager{
+		labels:   map[string]map[string]string{"db": {"team": "core"}},
+		payloads: map[string][]byte{"projects/p/secrets/db/versions/latest": []byte("v")},
+	}
+	s := configure(t, newFakeServer(t, fake), nil)
+
+	secret, err := s.Get(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := secret.Metadata.Tags["team"]; got != "core" {
+		t.Errorf("Tags[team] = %q, want %q", got, "core")
+	}
+	if want := "projects/p/secrets/db/versions/latest"; secret.Metadata.SourceID != want {
+		t.Errorf("SourceID = %q, want %q", secret.Metadata.SourceID, want)
+	}
+}
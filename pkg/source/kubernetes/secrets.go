@@ -0,0 +1,252 @@
+// Package kubernetes provides the Kubernetes Secrets source implementation.
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+const (
+	// SourceName is the identifier for this source.
+	SourceName = "kubernetes-secrets"
+
+	// lastAppliedAnnotation holds the full manifest (including secret data)
+	// when a Secret was created with kubectl apply. It is never exported.
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// labelTagPrefix and annotationTagPrefix prefix the tag keys of labels
+	// and annotations.
+	labelTagPrefix      = "label:"
+	annotationTagPrefix = "annotation:"
+
+	// listPageSize is the number of Secrets requested per List call.
+	listPageSize = 500
+)
+
+// DefaultSkipTypes are the Secret types skipped unless skip_types is set.
+var DefaultSkipTypes = []string{
+	string(corev1.SecretTypeServiceAccountToken),
+	"helm.sh/release.v1",
+}
+
+func init() {
+	// Register this source with the default registry
+	source.Register(SourceName, NewSource)
+}
+
+// Source implements the source.Source interface for Kubernetes Secrets.
+type Source struct {
+	client        kubernetes.Interface
+	namespaces    []string // Namespaces to read; empty means all namespaces
+	labelSelector string
+	skipTypes     map[string]bool
+}
+
+// NewSource creates a new Kubernetes Secrets source.
+func NewSource() source.Source {
+	return &Source{}
+}
+
+// NewSourceWithClient creates a Kubernetes Secrets source that uses the given
+// clientset (e.g., client-go's fake clientset) instead of a kubeconfig.
+func NewSourceWithClient(client kubernetes.Interface) *Source {
+	return &Source{client: client}
+}
+
+// Name returns the source identifier.
+func (s *Source) Name() string {
+	return SourceName
+}
+
+// Description returns a human-readable description.
+func (s *Source) Description() string {
+	return "Kubernetes Secrets"
+}
+
+// Configure initializes the source with cluster credentials and selectors.
+// Options:
+//   - kubeconfig: Path to a kubeconfig file (optional, defaults to in-cluster
+//     config, KUBECONFIG or ~/.kube/config)
+//   - context: kubeconfig context to use (optional)
+//   - namespaces: Comma separated namespaces to read (optional, default: all)
+//   - label_selector: Label selector, e.g. "app=web,tier!=cache" (optional)
+//   - skip_types: Comma separated Secret types to skip (default: service
+//     account tokens and Helm release secrets; set empty to skip none)
+func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) error {
+	s.namespaces = source.StringSliceOption(opts, "namespaces")
+	s.labelSelector = source.StringOption(opts, "label_selector")
+
+	skipTypes := DefaultSkipTypes
+	if _, ok := opts["skip_types"]; ok {
+		skipTypes = source.StringSliceOption(opts, "skip_types")
+	}
+	s.skipTypes = make(map[string]bool, len(skipTypes))
+	for _, t := range skipTypes {
+		s.skipTypes[t] = true
+	}
+
+	// A clientset injected with NewSourceWithClient takes precedence
+	if s.client != nil {
+		return nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig := source.StringOption(opts, "kubeconfig"); kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: source.StringOption(opts, "context"),
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load Kubernetes config: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	s.client = client
+	return nil
+}
+
+// List returns information about Secrets matching the given patterns.
+// Paths have the form <namespace>/<name>.
+func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	// Create filter
+	pathFilter, err := filter.FromPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	namespaces := s.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var secrets []source.SecretInfo
+	for _, ns := range namespaces {
+		listOpts := metav1.ListOptions{
+			LabelSelector: s.labelSelector,
+			Limit:         listPageSize,
+		}
+
+		for {
+			page, err := s.client.CoreV1().Secrets(ns).List(ctx, listOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list secrets: %w", err)
+			}
+
+			for i := range page.Items {
+				item := &page.Items[i]
+				if s.skipTypes[string(item.Type)] {
+					continue
+				}
+
+				path := item.Namespace + "/" + item.Name
+
+				// Apply filter
+				if !pathFilter.Matches(path) {
+					continue
+				}
+
+				secrets = append(secrets, source.SecretInfo{
+					Path:        path,
+					Description: string(item.Type),
+					Tags:        objectTags(item),
+				})
+			}
+
+			if page.Continue == "" {
+				break
+			}
+			listOpts.Continue = page.Continue
+		}
+	}
+
+	return secrets, nil
+}
+
+// Get retrieves a single Secret by its <namespace>/<name> path.
+func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	ns, name, ok := strings.Cut(path, "/")
+	if !ok || ns == "" || name == "" {
+		return nil, fmt.Errorf("invalid secret path %s (expected namespace/name)", path)
+	}
+
+	item, err := s.client.CoreV1().Secrets(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", path, err)
+	}
+
+	secret := &source.Secret{
+		Path: path,
+		Data: make(map[string]interface{}, len(item.Data)+len(item.StringData)),
+		Metadata: source.SecretMetadata{
+			SourceID:    string(item.UID),
+			ContentType: string(item.Type),
+			Tags:        objectTags(item),
+		},
+	}
+
+	// The API server returns data already base64-decoded; keep text as-is
+	// and re-encode binary values so they survive JSON
+	for key, value := range item.Data {
+		if utf8.Valid(value) {
+			secret.Data[key] = string(value)
+		} else {
+			secret.Data[key] = base64.StdEncoding.EncodeToString(value)
+		}
+	}
+
+	if !item.CreationTimestamp.IsZero() {
+		createdAt := item.CreationTimestamp.UTC()
+		secret.Metadata.CreatedAt = &createdAt
+	}
+
+	return secret, nil
+}
+
+// Export retrieves all Secrets matching the given patterns.
+func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	return source.ExportConcurrently(ctx, s, patterns, source.DefaultExportWorkers)
+}
+
+// objectTags merges a Secret's labels and annotations into tags, dropping the
+// last-applied-configuration annotation which may contain secret data. Keys
+// are prefixed with labelTagPrefix or annotationTagPrefix, so a label and an
+// annotation with the same key are both kept.
+func objectTags(item *corev1.Secret) map[string]string {
+	tags := make(map[string]string, len(item.Labels)+len(item.Annotations))
+	for k, v := range item.Labels {
+		tags[labelTagPrefix+k] = v
+	}
+	for k, v := range item.Annotations {
+		if k == lastAppliedAnnotation {
+			continue
+		}
+		tags[annotationTagPrefix+k] = v
+	}
+	return tags
+}
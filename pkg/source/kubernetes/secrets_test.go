@@ -0,0 +1,179 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testSecrets returns the Secrets the fake clientset is seeded with.
+func testSecrets() []runtime.Object {
+	return []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "shop",
+				Name:      "db",
+				UID:       "uid-db",
+				Labels:    map[string]string{"app": "web"},
+				Annotations: map[string]string{
+					"app":                 "annotated",
+					lastAppliedAnnotation: `{"data":{"password":"leak"}}`,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				"password": []byte("s3cret"),
+				"cert":     {0xff, 0xfe},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "shop",
+				Name:      "cache",
+				Labels:    map[string]string{"app": "cache"},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{"token": []byte("t")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "sa-token"},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "api"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"key": []byte("k")},
+		},
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      map[string]interface{}
+		patterns  []string
+		wantPaths []string
+	}{
+		{
+			name:      "all namespaces skip service account tokens",
+			wantPaths: []string{"billing/api", "shop/cache", "shop/db"},
+		},
+		{
+			name:      "namespaces",
+			opts:      map[string]interface{}{"namespaces": "billing"},
+			wantPaths: []string{"billing/api"},
+		},
+		{
+			name:      "label selector",
+			opts:      map[string]interface{}{"label_selector": "app=web"},
+			wantPaths: []string{"shop/db"},
+		},
+		{
+			name:      "skip no types",
+			opts:      map[string]interface{}{"skip_types": ""},
+			wantPaths: []string{"billing/api", "shop/cache", "shop/db", "shop/sa-token"},
+		},
+		{
+			name:      "pattern",
+			patterns:  []string{"shop/*"},
+			wantPaths: []string{"shop/cache", "shop/db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSourceWithClient(fake.NewSimpleClientset(testSecrets()...))
+			ctx := context.Background()
+			opts := tt.opts
+			if opts == nil {
+				opts = map[string]interface{}{}
+			}
+			if err := s.Configure(ctx, opts); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			infos, err := s.List(ctx, tt.patterns)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var paths []string
+			for _, info := range infos {
+				paths = append(paths, info.Path)
+			}
+			sort.Strings(paths)
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("List() paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := NewSourceWithClient(fake.NewSimpleClientset(testSecrets()...))
+	ctx := context.Background()
+	if err := s.Configure(ctx, map[string]interface{}{}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	secret, err := s.Get(ctx, "shop/db")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	wantData := map[string]interface{}{
+		"password": "s3cret",
+		"cert":     base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe}),
+	}
+	if !reflect.DeepEqual(secret.Data, wantData) {
+		t.Errorf("Data = %v, want %v", secret.Data, wantData)
+	}
+
+	wantTags := map[string]string{
+		"label:app":      "web",
+		"annotation:app": "annotated",
+	}
+	if !reflect.DeepEqual(secret.Metadata.Tags, wantTags) {
+		t.Errorf("Tags = %v, want %v", secret.Metadata.Tags, wantTags)
+	}
+	if secret.Metadata.SourceID != "uid-db" {
+		t.Errorf("SourceID = %q, want %q", secret.Metadata.SourceID, "uid-db")
+	}
+	if secret.Metadata.ContentType != string(corev1.SecretTypeOpaque) {
+		t.Errorf("ContentType = %q, want %q", secret.Metadata.ContentType, corev1.SecretTypeOpaque)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "no namespace", path: "db", wantErr: "expected namespace/name"},
+		{name: "empty name", path: "shop/", wantErr: "expected namespace/name"},
+		{name: "missing", path: "shop/missing", wantErr: "failed to get secret shop/missing"},
+	}
+
+	s := NewSourceWithClient(fake.NewSimpleClientset(testSecrets()...))
+	ctx := context.Background()
+	if err := s.Configure(ctx, map[string]interface{}{}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Get(ctx, tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Get(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
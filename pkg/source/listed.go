@@ -0,0 +1,35 @@
+package source
+
+import "context"
+
+// listedSource exports the secrets of a listing taken earlier instead of
+// listing the wrapped source again.
+type listedSource struct {
+	Source
+	infos []SecretInfo
+}
+
+// WithListing wraps src so List and Export use infos, a listing of src for
+// the patterns that will be exported, instead of listing src again. A
+// BulkExporter lists as part of its Export, so it is returned unchanged.
+func WithListing(src Source, infos []SecretInfo) Source {
+	if _, ok := Unwrap(src).(BulkExporter); ok {
+		return src
+	}
+	return &listedSource{Source: src, infos: infos}
+}
+
+// List returns the listing src was wrapped with.
+func (s *listedSource) List(ctx context.Context, patterns []string) ([]SecretInfo, error) {
+	return s.infos, nil
+}
+
+// Export retrieves the listed secrets.
+func (s *listedSource) Export(ctx context.Context, patterns []string) (<-chan *Secret, <-chan error) {
+	return ExportConcurrently(ctx, s, patterns, DefaultExportWorkers)
+}
+
+// Unwrap returns the wrapped source.
+func (s *listedSource) Unwrap() Source {
+	return s.Source
+}
@@ -0,0 +1,65 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StringOption returns the string option with the given key, or "" if it is
+// not set.
+func StringOption(opts map[string]interface{}, key string) string {
+	switch v := opts[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// BoolOption returns the boolean option with the given key. String values
+// (e.g., from --source-opt) are parsed with strconv.ParseBool.
+func BoolOption(opts map[string]interface{}, key string) (bool, error) {
+	switch v := opts[key].(type) {
+	case bool:
+		return v, nil
+	case string:
+		if v == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for %s: %q (expected true or false)", key, v)
+		}
+		return b, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid value for %s: %v (expected true or false)", key, v)
+	}
+}
+
+// StringSliceOption returns the list option with the given key. String
+// values are split on commas.
+func StringSliceOption(opts map[string]interface{}, key string) []string {
+	switch v := opts[key].(type) {
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		parts := strings.Split(v, ",")
+		result := make([]string, 0, len(parts))
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				result = append(result, p)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
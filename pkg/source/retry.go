@@ -0,0 +1,98 @@
+package source
+
+import (
+	"context"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
+)
+
+// retryingSource rate limits and retries the List and Get calls of a Source.
+type retryingSource struct {
+	Source
+	retryer *retry.Retryer
+}
+
+// BulkExporter is implemented by sources whose Export reads secrets in bulk
+// instead of through List and Get, such as a recursive listing that returns
+// values. SetRetryer makes every API call of that Export go through retryer.
+type BulkExporter interface {
+	Source
+	SetRetryer(retryer *retry.Retryer)
+}
+
+// PageRetrier is implemented by sources whose List reads several pages.
+// SetListRetryer makes List retry each page read with retryer, so a failure
+// on a late page does not repeat the pages before it.
+type PageRetrier interface {
+	Source
+	SetListRetryer(retryer *retry.Retryer)
+}
+
+// WithRetry wraps src so every List and Get call goes through retryer. The
+// List of a PageRetrier retries its pages itself, handed retryer. Export
+// uses the bulk Export of a BulkExporter, handing it retryer, and is
+// otherwise implemented on top of the wrapped List and Get with
+// ExportConcurrently. Use Unwrap to reach the underlying source.
+func WithRetry(src Source, retryer *retry.Retryer) Source {
+	if retryer == nil {
+		return src
+	}
+	if bulk, ok := src.(BulkExporter); ok {
+		bulk.SetRetryer(retryer)
+	}
+	if pager, ok := Unwrap(src).(PageRetrier); ok {
+		pager.SetListRetryer(retryer)
+	}
+	return &retryingSource{Source: src, retryer: retryer}
+}
+
+// List returns information about secrets matching the given patterns.
+func (s *retryingSource) List(ctx context.Context, patterns []string) ([]SecretInfo, error) {
+	if _, ok := Unwrap(s.Source).(PageRetrier); ok {
+		return s.Source.List(ctx, patterns)
+	}
+
+	var infos []SecretInfo
+	err := s.retryer.Do(ctx, func() error {
+		var err error
+		infos, err = s.Source.List(ctx, patterns)
+		return err
+	})
+	return infos, err
+}
+
+// Get retrieves a single secret by path.
+func (s *retryingSource) Get(ctx context.Context, path string) (*Secret, error) {
+	var secret *Secret
+	err := s.retryer.Do(ctx, func() error {
+		var err error
+		secret, err = s.Source.Get(ctx, path)
+		return err
+	})
+	return secret, err
+}
+
+// Export retrieves all secrets matching the given patterns.
+func (s *retryingSource) Export(ctx context.Context, patterns []string) (<-chan *Secret, <-chan error) {
+	if _, ok := s.Source.(BulkExporter); ok {
+		return s.Source.Export(ctx, patterns)
+	}
+	return ExportConcurrently(ctx, s, patterns, DefaultExportWorkers)
+}
+
+// Unwrap returns the wrapped source.
+func (s *retryingSource) Unwrap() Source {
+	return s.Source
+}
+
+// Unwrap returns the innermost source of a wrapped source such as one
+// returned by WithRetry, or src itself.
+func Unwrap(src Source) Source {
+	for {
+		w, ok := src.(interface{ Unwrap() Source })
+		if !ok {
+			return src
+		}
+		src = w.Unwrap()
+	}
+}
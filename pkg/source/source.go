@@ -16,6 +16,25 @@ type Secret struct {
 
 	// Metadata from the source system
 	Metadata SecretMetadata `json:"metadata,omitempty"`
+
+	// Versions are the previous versions of the secret, oldest first. Data
+	// holds the current version. Only filled when exporting version history.
+	Versions []SecretVersion `json:"versions,omitempty"`
+}
+
+// SecretVersion is a previous version of a secret.
+type SecretVersion struct {
+	// VersionID is the version identifier in the source
+	VersionID string `json:"version_id"`
+
+	// Labels are the source's labels for the version (e.g., "AWSPREVIOUS")
+	Labels []string `json:"labels,omitempty"`
+
+	// CreatedAt is when the version was created in the source
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// Data contains the key-value pairs of the version
+	Data map[string]interface{} `json:"data"`
 }
 
 // SecretMetadata contains optional metadata about a secret from the source.
@@ -29,6 +48,15 @@ type SecretMetadata struct {
 	// Tags are key-value tags from the source
 	Tags map[string]string `json:"tags,omitempty"`
 
+	// ContentType is the content type of the secret value, if the source records one
+	ContentType string `json:"content_type,omitempty"`
+
+	// Enabled reports whether the secret is enabled in the source, if the source supports disabling secrets
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ExpiresAt is when the secret expires in the source, if set
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
 	// CreatedAt is when the secret was created in the source
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 
@@ -62,7 +90,8 @@ type Source interface {
 	Configure(ctx context.Context, opts map[string]interface{}) error
 
 	// List returns information about secrets matching the given patterns.
-	// Patterns support glob syntax (e.g., "myapp/*", "**").
+	// Patterns support glob syntax (e.g., "myapp/*", "**"). Patterns
+	// prefixed with "!" exclude matching paths (see filter.CombinePatterns).
 	// If patterns is empty, all secrets are returned.
 	List(ctx context.Context, patterns []string) ([]SecretInfo, error)
 
@@ -71,9 +100,19 @@ type Source interface {
 
 	// Export retrieves all secrets matching the given patterns.
 	// Returns a channel of secrets and a channel of errors.
-	// The caller should consume both channels until they are closed.
+	// The caller must consume both channels concurrently until they are closed.
 	Export(ctx context.Context, patterns []string) (<-chan *Secret, <-chan error)
 }
 
+// VersionedSource is implemented by sources that retain previous versions of
+// secrets.
+type VersionedSource interface {
+	Source
+
+	// GetVersions returns the previous versions of a secret, oldest first,
+	// excluding the current version returned by Get.
+	GetVersions(ctx context.Context, path string) ([]SecretVersion, error)
+}
+
 // SourceFactory creates new Source instances.
 type SourceFactory func() Source
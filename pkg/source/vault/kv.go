@@ -0,0 +1,312 @@
+// Package vault provides the OpenBao/HashiCorp Vault KV source implementation.
+package vault
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/filter"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target/openbao"
+)
+
+const (
+	// SourceName is the identifier for this source.
+	SourceName = "vault-kv"
+)
+
+func init() {
+	// Register this source with the default registry
+	source.Register(SourceName, NewSource)
+}
+
+// Source implements the source.Source interface for a KV v1 or v2 mount in
+// OpenBao or HashiCorp Vault.
+type Source struct {
+	client    *api.Client
+	address   string
+	mount     string
+	root      string        // Sub-path of the mount to export; secret paths are relative to it
+	kvVersion int           // 1 or 2
+	kv        target.Target // KV target of the mount, used to walk it
+}
+
+// NewSource creates a new Vault KV source.
+func NewSource() source.Source {
+	return &Source{}
+}
+
+// Name returns the source identifier.
+func (s *Source) Name() string {
+	return SourceName
+}
+
+// Description returns a human-readable description.
+func (s *Source) Description() string {
+	return "OpenBao/Vault KV secrets engine (v1 or v2)"
+}
+
+// Configure initializes the source with the server address, token and mount.
+// Options:
+//   - address: Server address (falls back to VAULT_ADDR or BAO_ADDR)
+//   - token: Authentication token (falls back to VAULT_TOKEN or BAO_TOKEN)
+//   - mount: KV mount path, e.g. "secret" (required)
+//   - path: Sub-path of the mount to export (optional, default: whole mount)
+//   - kv_version: "1" or "2" (optional, detected from the mount by default)
+//   - namespace: Namespace of the mount (optional)
+//   - header: Custom headers, comma separated 'Key: Value' pairs (optional)
+//   - tls_skip_verify: Skip TLS certificate verification (optional)
+func (s *Source) Configure(ctx context.Context, opts map[string]interface{}) error {
+	s.mount = strings.Trim(source.StringOption(opts, "mount"), "/")
+	if s.mount == "" {
+		return fmt.Errorf("mount is required")
+	}
+	s.root = strings.Trim(source.StringOption(opts, "path"), "/")
+
+	apiConfig := api.DefaultConfig()
+
+	s.address = firstNonEmpty(source.StringOption(opts, "address"), os.Getenv("VAULT_ADDR"), os.Getenv("BAO_ADDR"))
+	if s.address == "" {
+		return fmt.Errorf("address is required (set the address option, VAULT_ADDR or BAO_ADDR)")
+	}
+	apiConfig.Address = s.address
+
+	skipVerify, err := source.BoolOption(opts, "tls_skip_verify")
+	if err != nil {
+		return err
+	}
+	if skipVerify {
+		apiConfig.HttpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	if token := firstNonEmpty(source.StringOption(opts, "token"), os.Getenv("VAULT_TOKEN"), os.Getenv("BAO_TOKEN")); token != "" {
+		client.SetToken(token)
+	}
+	if client.Token() == "" {
+		return fmt.Errorf("token is required (set the token option, VAULT_TOKEN or BAO_TOKEN)")
+	}
+
+	if ns := source.StringOption(opts, "namespace"); ns != "" {
+		client.SetNamespace(ns)
+	}
+
+	headers, err := openbao.ParseHeaders(source.StringSliceOption(opts, "header"))
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	for key, value := range headers {
+		client.AddHeader(key, value)
+	}
+
+	s.client = client
+
+	switch version := source.StringOption(opts, "kv_version"); version {
+	case "1":
+		s.kvVersion = 1
+	case "2":
+		s.kvVersion = 2
+	case "":
+		if s.kvVersion, err = s.detectKVVersion(ctx); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid kv_version: %s (expected 1 or 2)", version)
+	}
+
+	if s.kvVersion == 1 {
+		s.kv = openbao.NewKVv1(conn{client}, s.mount)
+	} else {
+		s.kv = openbao.NewKVv2(conn{client}, s.mount)
+	}
+
+	return nil
+}
+
+// conn is a target.Conn sending requests directly on the source's client.
+// Retries and rate limiting are left to source.WithRetry.
+type conn struct {
+	client *api.Client
+}
+
+// Do calls fn with the client's logical API.
+func (c conn) Do(ctx context.Context, fn func(*api.Logical) error) error {
+	return fn(c.client.Logical())
+}
+
+// Health checks the server health.
+func (c conn) Health(ctx context.Context) error {
+	health, err := c.client.Sys().HealthWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check server health: %w", err)
+	}
+	if health.Sealed {
+		return fmt.Errorf("server is sealed")
+	}
+	return nil
+}
+
+// detectKVVersion reads the mount's options to determine its KV version.
+func (s *Source) detectKVVersion(ctx context.Context) (int, error) {
+	mount, err := s.client.Logical().ReadWithContext(ctx, "sys/internal/ui/mounts/"+s.mount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect KV version of mount %s (set kv_version to skip detection): %w", s.mount, err)
+	}
+	if mount == nil || mount.Data == nil {
+		return 0, fmt.Errorf("mount %s not found", s.mount)
+	}
+
+	if mountType, _ := mount.Data["type"].(string); mountType != "kv" && mountType != "generic" {
+		return 0, fmt.Errorf("mount %s is not a KV mount (type %s)", s.mount, mountType)
+	}
+
+	if options, ok := mount.Data["options"].(map[string]interface{}); ok {
+		if version, _ := options["version"].(string); version == "2" {
+			return 2, nil
+		}
+	}
+
+	return 1, nil
+}
+
+// List returns information about secrets matching the given patterns by
+// recursively walking the mount.
+func (s *Source) List(ctx context.Context, patterns []string) ([]source.SecretInfo, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	// Create filter
+	pathFilter, err := filter.FromPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	root := s.join("")
+	paths, err := target.Walk(ctx, s.kv, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []source.SecretInfo
+	for _, path := range paths {
+		// Apply filter to the path relative to the source root
+		path = strings.TrimPrefix(path, root)
+		if pathFilter.Matches(path) {
+			secrets = append(secrets, source.SecretInfo{Path: path})
+		}
+	}
+
+	return secrets, nil
+}
+
+// Get retrieves a single secret by path, including its custom metadata.
+func (s *Source) Get(ctx context.Context, path string) (*source.Secret, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("source not configured")
+	}
+
+	fullPath := s.join(path)
+
+	secret := &source.Secret{
+		Path: path,
+		Metadata: source.SecretMetadata{
+			SourceID: s.mount + "/" + fullPath,
+		},
+	}
+
+	if s.kvVersion == 1 {
+		kvSecret, err := s.client.KVv1(s.mount).Get(ctx, fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s: %w", path, err)
+		}
+		secret.Data = kvSecret.Data
+		return secret, nil
+	}
+
+	kv := s.client.KVv2(s.mount)
+
+	kvSecret, err := kv.Get(ctx, fullPath)
+	if err != nil {
+		if errors.Is(err, api.ErrSecretNotFound) {
+			return nil, fmt.Errorf("failed to get secret %s: current version is deleted or destroyed", path)
+		}
+		return nil, fmt.Errorf("failed to get secret %s: %w", path, err)
+	}
+	secret.Data = kvSecret.Data
+	if secret.Data == nil {
+		return nil, fmt.Errorf("failed to get secret %s: current version is deleted or destroyed", path)
+	}
+
+	if len(kvSecret.CustomMetadata) > 0 {
+		secret.Metadata.Tags = make(map[string]string, len(kvSecret.CustomMetadata))
+		for k, v := range kvSecret.CustomMetadata {
+			secret.Metadata.Tags[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	if vm := kvSecret.VersionMetadata; vm != nil {
+		secret.Metadata.SourceID = fmt.Sprintf("%s/%s?version=%d", s.mount, fullPath, vm.Version)
+		if !vm.CreatedTime.IsZero() {
+			updatedAt := vm.CreatedTime
+			secret.Metadata.UpdatedAt = &updatedAt
+		}
+	}
+
+	// Get additional metadata
+	meta, err := kv.GetMetadata(ctx, fullPath)
+	if err == nil && !meta.CreatedTime.IsZero() {
+		createdAt := meta.CreatedTime
+		secret.Metadata.CreatedAt = &createdAt
+	}
+
+	return secret, nil
+}
+
+// Export retrieves all secrets matching the given patterns.
+func (s *Source) Export(ctx context.Context, patterns []string) (<-chan *source.Secret, <-chan error) {
+	return source.ExportConcurrently(ctx, s, patterns, source.DefaultExportWorkers)
+}
+
+// Address returns the configured server address.
+func (s *Source) Address() string {
+	return s.address
+}
+
+// KVVersion returns the KV version of the configured mount.
+func (s *Source) KVVersion() int {
+	return s.kvVersion
+}
+
+// join prefixes a relative path with the configured root path.
+func (s *Source) join(relPath string) string {
+	if s.root == "" {
+		return relPath
+	}
+	return s.root + "/" + relPath
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package source
+
+import (
+	"context"
+	"fmt"
+)
+
+// versionedSource fills Secret.Versions on every Get.
+type versionedSource struct {
+	VersionedSource
+}
+
+// WithVersions wraps src so Get also retrieves the previous versions of each
+// secret. It fails if src does not retain versions.
+func WithVersions(src Source) (Source, error) {
+	versioned, ok := src.(VersionedSource)
+	if !ok {
+		return nil, fmt.Errorf("source %s does not support exporting versions", src.Name())
+	}
+	return &versionedSource{VersionedSource: versioned}, nil
+}
+
+// Get retrieves a single secret by path with its previous versions.
+func (s *versionedSource) Get(ctx context.Context, path string) (*Secret, error) {
+	secret, err := s.VersionedSource.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Versions, err = s.GetVersions(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Export retrieves all secrets matching the given patterns.
+func (s *versionedSource) Export(ctx context.Context, patterns []string) (<-chan *Secret, <-chan error) {
+	return ExportConcurrently(ctx, s, patterns, DefaultExportWorkers)
+}
+
+// Unwrap returns the wrapped source.
+func (s *versionedSource) Unwrap() Source {
+	return s.VersionedSource
+}
@@ -0,0 +1,321 @@
+package openbao
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Auth method names.
+const (
+	AuthToken      = "token"
+	AuthAppRole    = "approle"
+	AuthKubernetes = "kubernetes"
+	AuthUserpass   = "userpass"
+	AuthCert       = "cert"
+	AuthJWT        = "jwt"
+)
+
+// DefaultKubernetesJWTPath is where Kubernetes mounts the service account token.
+const DefaultKubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// AuthMethod obtains a token for the client.
+type AuthMethod interface {
+	// Name returns the method identifier (e.g., "approle")
+	Name() string
+
+	// Login authenticates and returns the auth response. The client is
+	// configured with the address, TLS settings and headers but no token.
+	Login(ctx context.Context, client *api.Client) (*api.Secret, error)
+
+	// CanRelogin reports whether Login can be called again to obtain a
+	// fresh token once the current one can no longer be renewed.
+	CanRelogin() bool
+}
+
+// TokenAuth uses an existing token.
+type TokenAuth struct {
+	// Token is the client token
+	Token string
+}
+
+// Name returns the method identifier.
+func (a *TokenAuth) Name() string {
+	return AuthToken
+}
+
+// Login looks up the token to learn its TTL and whether it is renewable. A
+// token that is rejected (403) fails the login, so an expired or invalid
+// token is reported once rather than as a permission error for every
+// secret. If the lookup fails for another reason, the token is used as-is.
+func (a *TokenAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.Token == "" {
+		return nil, fmt.Errorf("token is empty")
+	}
+
+	client.SetToken(a.Token)
+	auth := &api.SecretAuth{ClientToken: a.Token}
+
+	self, err := client.Auth().Token().LookupSelfWithContext(ctx)
+	if code, ok := StatusCode(err); ok && code == http.StatusForbidden {
+		return nil, fmt.Errorf("token is invalid, expired, or not allowed to look itself up: %w", err)
+	}
+	if err != nil || self == nil {
+		return &api.Secret{Auth: auth}, nil
+	}
+
+	if ttl, err := self.TokenTTL(); err == nil {
+		auth.LeaseDuration = int(ttl.Seconds())
+	}
+	if renewable, err := self.TokenIsRenewable(); err == nil {
+		auth.Renewable = renewable
+	}
+
+	return &api.Secret{Auth: auth}, nil
+}
+
+// CanRelogin returns false: a static token cannot be replaced.
+func (a *TokenAuth) CanRelogin() bool {
+	return false
+}
+
+// AppRoleAuth logs in with an AppRole role ID and secret ID.
+type AppRoleAuth struct {
+	// Mount is the auth mount path (default: "approle")
+	Mount string
+
+	// RoleID is the role ID
+	RoleID string
+
+	// SecretID is the secret ID (optional if the role does not require one)
+	SecretID string
+}
+
+// Name returns the method identifier.
+func (a *AppRoleAuth) Name() string {
+	return AuthAppRole
+}
+
+// Login logs in at auth/<mount>/login.
+func (a *AppRoleAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.RoleID == "" {
+		return nil, fmt.Errorf("role ID is required")
+	}
+	data := map[string]interface{}{"role_id": a.RoleID}
+	if a.SecretID != "" {
+		data["secret_id"] = a.SecretID
+	}
+	return login(ctx, client, authMount(a.Mount, AuthAppRole)+"/login", data)
+}
+
+// CanRelogin returns true.
+func (a *AppRoleAuth) CanRelogin() bool {
+	return true
+}
+
+// KubernetesAuth logs in with a Kubernetes service account token.
+type KubernetesAuth struct {
+	// Mount is the auth mount path (default: "kubernetes")
+	Mount string
+
+	// Role is the OpenBao role to log in as
+	Role string
+
+	// JWTPath is the service account token file (default: DefaultKubernetesJWTPath)
+	JWTPath string
+}
+
+// Name returns the method identifier.
+func (a *KubernetesAuth) Name() string {
+	return AuthKubernetes
+}
+
+// Login reads the service account token and logs in at auth/<mount>/login.
+// The token file is read on every login because Kubernetes rotates it.
+func (a *KubernetesAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.Role == "" {
+		return nil, fmt.Errorf("role is required")
+	}
+
+	path := a.JWTPath
+	if path == "" {
+		path = DefaultKubernetesJWTPath
+	}
+	jwt, err := ReadCredentialFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	return login(ctx, client, authMount(a.Mount, AuthKubernetes)+"/login", map[string]interface{}{
+		"role": a.Role,
+		"jwt":  jwt,
+	})
+}
+
+// CanRelogin returns true.
+func (a *KubernetesAuth) CanRelogin() bool {
+	return true
+}
+
+// UserpassAuth logs in with a username and password.
+type UserpassAuth struct {
+	// Mount is the auth mount path (default: "userpass")
+	Mount string
+
+	// Username is the user to log in as
+	Username string
+
+	// Password is the user's password
+	Password string
+}
+
+// Name returns the method identifier.
+func (a *UserpassAuth) Name() string {
+	return AuthUserpass
+}
+
+// Login logs in at auth/<mount>/login/<username>.
+func (a *UserpassAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.Username == "" || a.Password == "" {
+		return nil, fmt.Errorf("username and password are required")
+	}
+	return login(ctx, client, authMount(a.Mount, AuthUserpass)+"/login/"+a.Username, map[string]interface{}{
+		"password": a.Password,
+	})
+}
+
+// CanRelogin returns true.
+func (a *UserpassAuth) CanRelogin() bool {
+	return true
+}
+
+// CertAuth logs in with the TLS client certificate configured in
+// Config.ClientCert and Config.ClientKey.
+type CertAuth struct {
+	// Mount is the auth mount path (default: "cert")
+	Mount string
+
+	// Role is the certificate role to log in as (optional, default: any matching role)
+	Role string
+}
+
+// Name returns the method identifier.
+func (a *CertAuth) Name() string {
+	return AuthCert
+}
+
+// Login logs in at auth/<mount>/login.
+func (a *CertAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	data := map[string]interface{}{}
+	if a.Role != "" {
+		data["name"] = a.Role
+	}
+	return login(ctx, client, authMount(a.Mount, AuthCert)+"/login", data)
+}
+
+// CanRelogin returns true.
+func (a *CertAuth) CanRelogin() bool {
+	return true
+}
+
+// JWTAuth logs in to the JWT/OIDC auth method with a token obtained
+// elsewhere, such as a CI job's OIDC ID token.
+type JWTAuth struct {
+	// Mount is the auth mount path (default: "jwt")
+	Mount string
+
+	// Role is the OpenBao role to log in as (optional, default: the mount's default role)
+	Role string
+
+	// JWT is the signed token
+	JWT string
+}
+
+// Name returns the method identifier.
+func (a *JWTAuth) Name() string {
+	return AuthJWT
+}
+
+// Login logs in at auth/<mount>/login.
+func (a *JWTAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	if a.JWT == "" {
+		return nil, fmt.Errorf("JWT is required")
+	}
+	data := map[string]interface{}{"jwt": a.JWT}
+	if a.Role != "" {
+		data["role"] = a.Role
+	}
+	return login(ctx, client, authMount(a.Mount, AuthJWT)+"/login", data)
+}
+
+// CanRelogin returns false: the supplied JWT is usually short-lived.
+func (a *JWTAuth) CanRelogin() bool {
+	return false
+}
+
+// login writes data to an auth login path and checks the response.
+func login(ctx context.Context, client *api.Client, path string, data map[string]interface{}) (*api.Secret, error) {
+	// Login requests must not carry a previous token
+	client.ClearToken()
+
+	secret, err := client.Logical().WriteWithContext(ctx, "auth/"+path, data)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("no token returned by auth/%s", path)
+	}
+	return secret, nil
+}
+
+// authMount returns the trimmed mount path, or def if it is empty.
+func authMount(mount, def string) string {
+	if mount = strings.Trim(mount, "/"); mount != "" {
+		return mount
+	}
+	return def
+}
+
+// ResolveToken returns the first token found in: token, tokenFile ("-" for
+// stdin), VAULT_TOKEN and BAO_TOKEN. It returns an empty string if there is
+// none.
+func ResolveToken(token, tokenFile string) (string, error) {
+	if token != "" {
+		return token, nil
+	}
+	if tokenFile != "" {
+		t, err := ReadCredentialFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		if t == "" {
+			return "", fmt.Errorf("token file %s is empty", tokenFile)
+		}
+		return t, nil
+	}
+	if t := os.Getenv("VAULT_TOKEN"); t != "" {
+		return t, nil
+	}
+	return os.Getenv("BAO_TOKEN"), nil
+}
+
+// ReadCredentialFile reads a token or password from path ("-" for stdin),
+// with surrounding whitespace removed.
+func ReadCredentialFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
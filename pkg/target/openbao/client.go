@@ -1,24 +1,37 @@
-// Package openbao provides the OpenBao/Vault KV v2 target client.
+// Package openbao provides the OpenBao/Vault client and its KV v1 and KV v2
+// targets.
 package openbao
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
-	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/api"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/retry"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
 )
 
-// Client wraps the Vault API client for OpenBao KV v2 operations.
+// Client wraps the Vault API client for OpenBao operations.
 type Client struct {
 	client    *api.Client
+	namespace string
 	mount     string
 	headers   map[string]string
+	retryer   *retry.Retryer
+	auth      AuthMethod
 	mu        sync.RWMutex
+
+	targetMu sync.Mutex
+	target   target.Target // Mount's target; nil until detected
+
+	stopRenew chan struct{}
+	renewDone chan struct{}
 }
 
 // Config holds the configuration for the OpenBao client.
@@ -26,20 +39,38 @@ type Config struct {
 	// Address is the OpenBao server address (e.g., "https://openbao.example.com:8200")
 	Address string
 
-	// Token is the authentication token
+	// Token is the authentication token, used when Auth is nil
 	Token string
 
-	// Mount is the KV v2 mount path (e.g., "secret")
+	// Auth logs in to obtain a token when Login is called (optional)
+	Auth AuthMethod
+
+	// Mount is the KV mount path (e.g., "secret")
 	Mount string
 
+	// Namespace is the namespace to log in to and write secrets in (optional)
+	Namespace string
+
 	// Headers are custom HTTP headers to add to all requests
 	Headers map[string]string
 
 	// TLSSkipVerify skips TLS certificate verification
 	TLSSkipVerify bool
 
+	// CACert is a PEM-encoded CA certificate file to verify the server with
+	CACert string
+
+	// ClientCert and ClientKey are a PEM-encoded TLS client certificate and
+	// key, required for cert auth
+	ClientCert string
+	ClientKey  string
+
 	// Timeout is the HTTP client timeout
 	Timeout time.Duration
+
+	// Retryer rate limits and retries every request (optional). When set,
+	// the Vault API client's built-in retries are disabled.
+	Retryer *retry.Retryer
 }
 
 // NewClient creates a new OpenBao client.
@@ -52,12 +83,20 @@ func NewClient(cfg Config) (*Client, error) {
 		apiConfig.Timeout = cfg.Timeout
 	}
 
+	if cfg.Retryer != nil {
+		apiConfig.MaxRetries = 0
+	}
+
 	// Configure TLS
-	if cfg.TLSSkipVerify {
-		apiConfig.HttpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+	if cfg.TLSSkipVerify || cfg.CACert != "" || cfg.ClientCert != "" || cfg.ClientKey != "" {
+		err := apiConfig.ConfigureTLS(&api.TLSConfig{
+			CACert:     cfg.CACert,
+			ClientCert: cfg.ClientCert,
+			ClientKey:  cfg.ClientKey,
+			Insecure:   cfg.TLSSkipVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
 		}
 	}
 
@@ -70,6 +109,11 @@ func NewClient(cfg Config) (*Client, error) {
 	// Set token
 	client.SetToken(cfg.Token)
 
+	namespace := strings.Trim(cfg.Namespace, "/")
+	if namespace != "" {
+		client.SetNamespace(namespace)
+	}
+
 	// Set custom headers
 	if len(cfg.Headers) > 0 {
 		for key, value := range cfg.Headers {
@@ -77,106 +121,266 @@ func NewClient(cfg Config) (*Client, error) {
 		}
 	}
 
+	auth := cfg.Auth
+	if auth == nil {
+		auth = &TokenAuth{Token: cfg.Token}
+	}
+
 	return &Client{
-		client:  client,
-		mount:   cfg.Mount,
-		headers: cfg.Headers,
+		client:    client,
+		namespace: namespace,
+		mount:     cfg.Mount,
+		headers:   cfg.Headers,
+		retryer:   cfg.Retryer,
+		auth:      auth,
 	}, nil
 }
 
-// WriteSecret writes a secret to KV v2.
-func (c *Client) WriteSecret(ctx context.Context, path string, data map[string]interface{}) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// WithNamespace returns a client for the child namespace ns of the client's
+// namespace. It shares the token, rate limiter and retries of c; closing it
+// has no effect.
+func (c *Client) WithNamespace(ns string) *Client {
+	namespace := strings.Trim(ns, "/")
+	if c.namespace != "" && namespace != "" {
+		namespace = c.namespace + "/" + namespace
+	} else if namespace == "" {
+		namespace = c.namespace
+	}
+
+	return &Client{
+		client:    c.client,
+		namespace: namespace,
+		mount:     c.mount,
+		headers:   c.headers,
+		retryer:   c.retryer,
+		auth:      c.auth,
+	}
+}
 
-	kv := c.client.KVv2(c.mount)
+// api returns the API client for the client's namespace. Clients made by
+// WithNamespace take a copy on every call so they pick up renewed tokens.
+func (c *Client) api() *api.Client {
+	if c.client.Namespace() == c.namespace {
+		return c.client
+	}
+	return c.client.WithNamespace(c.namespace)
+}
 
-	_, err := kv.Put(ctx, path, data)
+// Login authenticates with the configured auth method and keeps the token
+// alive in the background: renewable tokens are renewed, and methods that
+// can log in again do so when the token reaches its maximum TTL. Call Close
+// to stop renewal.
+func (c *Client) Login(ctx context.Context) error {
+	secret, err := c.login(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to write secret to %s: %w", path, err)
+		return fmt.Errorf("%s login failed: %w", c.auth.Name(), err)
 	}
 
+	c.stopRenew = make(chan struct{})
+	c.renewDone = make(chan struct{})
+	go c.keepAlive(secret)
+
 	return nil
 }
 
-// WriteSecretCAS writes a secret using Check-And-Set (CAS).
-// If cas is 0, the write will only succeed if the key doesn't exist.
-func (c *Client) WriteSecretCAS(ctx context.Context, path string, data map[string]interface{}, cas int) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// keepAlive renews the token of secret until it expires, logging in again
+// when the auth method allows it.
+func (c *Client) keepAlive(secret *api.Secret) {
+	defer close(c.renewDone)
+
+	for {
+		if secret.Auth.LeaseDuration <= 0 {
+			// Tokens without a TTL never expire
+			return
+		}
 
-	kv := c.client.KVv2(c.mount)
+		watcher, err := c.client.NewLifetimeWatcher(&api.LifetimeWatcherInput{Secret: secret})
+		if err != nil {
+			warnf("cannot renew the OpenBao token, it expires in %ds: %v", secret.Auth.LeaseDuration, err)
+			return
+		}
+		go watcher.Start()
+
+	renewing:
+		for {
+			select {
+			case <-c.stopRenew:
+				watcher.Stop()
+				return
+			case <-watcher.RenewCh():
+			case err := <-watcher.DoneCh():
+				// Renewal is no longer possible; the token is about to expire
+				if err != nil {
+					warnf("failed to renew the OpenBao token: %v", err)
+				}
+				break renewing
+			}
+		}
+		watcher.Stop()
 
-	_, err := kv.Put(ctx, path, data, api.WithCheckAndSet(cas))
-	if err != nil {
-		return fmt.Errorf("failed to write secret to %s: %w", path, err)
+		if !c.auth.CanRelogin() {
+			warnf("the OpenBao token can no longer be renewed and is about to expire; requests will then be denied")
+			return
+		}
+
+		secret, err = c.relogin()
+		if err != nil {
+			warnf("%s login to replace the expiring OpenBao token failed; requests will be denied once it expires: %v", c.auth.Name(), err)
+			return
+		}
 	}
+}
 
-	return nil
+// warnf reports a token renewal problem on stderr. Renewal runs in the
+// background, so it cannot return an error to the operation in progress.
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "\n  Warning: "+format+"\n", args...)
 }
 
-// ReadSecret reads a secret from KV v2.
-func (c *Client) ReadSecret(ctx context.Context, path string) (map[string]interface{}, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// relogin replaces the client token with a fresh one.
+func (c *Client) relogin() (*api.Secret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return c.login(ctx)
+}
 
-	kv := c.client.KVv2(c.mount)
+// login runs the auth method on a copy of the API client, so requests in
+// flight keep their token, and then switches the client to the new token.
+func (c *Client) login(ctx context.Context) (*api.Secret, error) {
+	loginClient, err := c.client.CloneWithHeaders()
+	if err != nil {
+		return nil, err
+	}
 
-	secret, err := kv.Get(ctx, path)
+	secret, err := c.auth.Login(ctx, loginClient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read secret from %s: %w", path, err)
+		return nil, err
 	}
+	c.client.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
 
-	if secret == nil || secret.Data == nil {
-		return nil, nil
+// Close stops token renewal.
+func (c *Client) Close() {
+	if c.stopRenew == nil {
+		return
 	}
+	close(c.stopRenew)
+	<-c.renewDone
+	c.stopRenew = nil
+}
+
+// RetryStats returns the retry and rate limiting counters of the client.
+func (c *Client) RetryStats() retry.Stats {
+	return c.retryer.Stats()
+}
 
-	return secret.Data, nil
+// StatusCode extracts the HTTP status code from an OpenBao API error.
+func StatusCode(err error) (int, bool) {
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode, true
+	}
+	return 0, false
 }
 
-// SecretExists checks if a secret exists at the given path.
-func (c *Client) SecretExists(ctx context.Context, path string) (bool, error) {
+// Do calls fn with the logical API of the client's namespace, rate limited
+// and retried by the client's retryer. It implements target.Conn.
+func (c *Client) Do(ctx context.Context, fn func(*api.Logical) error) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	kv := c.client.KVv2(c.mount)
+	logical := c.api().Logical()
+	return c.retryer.Do(ctx, func() error {
+		return fn(logical)
+	})
+}
 
-	secret, err := kv.Get(ctx, path)
+// Target returns the target for the client's mount, detecting its KV version
+// on first use.
+func (c *Client) Target(ctx context.Context) (target.Target, error) {
+	c.targetMu.Lock()
+	defer c.targetMu.Unlock()
+
+	if c.target != nil {
+		return c.target, nil
+	}
+
+	version, err := c.detectKVVersion(ctx)
 	if err != nil {
-		// Check if it's a "secret not found" error
-		if strings.Contains(err.Error(), "secret not found") {
-			return false, nil
-		}
-		return false, fmt.Errorf("failed to check secret at %s: %w", path, err)
+		return nil, err
+	}
+
+	name := TargetKVv2
+	if version == 1 {
+		name = TargetKVv1
+	}
+	t, err := target.Get(name, c, c.mount)
+	if err != nil {
+		return nil, err
 	}
 
-	return secret != nil, nil
+	c.target = t
+	return t, nil
 }
 
-// ListSecrets lists secrets at the given path using the logical client.
+// ListSecrets returns the paths of all secrets below the folder path,
+// walking its subfolders recursively. path is empty for the whole mount;
+// the returned paths include it.
 func (c *Client) ListSecrets(ctx context.Context, path string) ([]string, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	kv, err := c.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return target.Walk(ctx, kv, path)
+}
 
-	// Use the logical client to list metadata
-	listPath := fmt.Sprintf("%s/metadata/%s", c.mount, path)
-	secret, err := c.client.Logical().ListWithContext(ctx, listPath)
+// detectKVVersion reads the mount's options to determine its KV version.
+func (c *Client) detectKVVersion(ctx context.Context) (int, error) {
+	var mount *api.Secret
+	err := c.Do(ctx, func(l *api.Logical) error {
+		var err error
+		mount, err = l.ReadWithContext(ctx, "sys/internal/ui/mounts/"+c.mount)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list secrets at %s: %w", path, err)
+		return 0, fmt.Errorf("failed to detect KV version of mount %s: %w", c.mount, err)
+	}
+	if mount == nil || mount.Data == nil {
+		return 0, fmt.Errorf("mount %s not found", c.mount)
 	}
 
+	if mountType, _ := mount.Data["type"].(string); mountType != "kv" && mountType != "generic" {
+		return 0, fmt.Errorf("mount %s is not a KV mount (type %s)", c.mount, mountType)
+	}
+
+	if options, ok := mount.Data["options"].(map[string]interface{}); ok {
+		if version, _ := options["version"].(string); version == "2" {
+			return 2, nil
+		}
+	}
+
+	return 1, nil
+}
+
+// KeysFromList extracts the keys from a LIST response. Keys ending in "/"
+// are folders.
+func KeysFromList(secret *api.Secret) []string {
 	if secret == nil || secret.Data == nil {
-		return []string{}, nil
+		return []string{}
 	}
 
 	keysRaw, ok := secret.Data["keys"]
 	if !ok {
-		return []string{}, nil
+		return []string{}
 	}
 
 	keysSlice, ok := keysRaw.([]interface{})
 	if !ok {
-		return []string{}, nil
+		return []string{}
 	}
 
 	result := make([]string, len(keysSlice))
@@ -184,7 +388,7 @@ func (c *Client) ListSecrets(ctx context.Context, path string) ([]string, error)
 		result[i] = fmt.Sprintf("%v", k)
 	}
 
-	return result, nil
+	return result
 }
 
 // Health checks the OpenBao server health.
@@ -209,6 +413,11 @@ func (c *Client) Address() string {
 	return c.client.Address()
 }
 
+// Namespace returns the namespace of the client, empty for the root namespace.
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
 // Mount returns the configured KV mount path.
 func (c *Client) Mount() string {
 	return c.mount
@@ -0,0 +1,111 @@
+package openbao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+)
+
+// The methods in this file predate the target abstraction and are kept for
+// library users. They delegate to the target returned by Client.Target, so
+// they work on KV v1 mounts too where the operation exists there.
+
+// SecretVersion is the current version of a KV v2 secret.
+//
+// Deprecated: Use target.Version.
+type SecretVersion = target.Version
+
+// WriteSecret writes a secret.
+//
+// Deprecated: Use Client.Target and target.Target.Write.
+func (c *Client) WriteSecret(ctx context.Context, path string, data map[string]interface{}) error {
+	kv, err := c.Target(ctx)
+	if err != nil {
+		return err
+	}
+	return kv.Write(ctx, path, data)
+}
+
+// WriteSecretCAS writes a secret using Check-And-Set (CAS).
+// If cas is 0, the write will only succeed if the key doesn't exist.
+//
+// Deprecated: Use Client.Target and target.VersionedTarget.WriteCAS.
+func (c *Client) WriteSecretCAS(ctx context.Context, path string, data map[string]interface{}, cas int) error {
+	kv, err := c.versionedTarget(ctx)
+	if err != nil {
+		return err
+	}
+	return kv.WriteCAS(ctx, path, data, cas)
+}
+
+// ReadSecret reads a secret. It returns nil if the secret does not exist or
+// its current version is deleted.
+//
+// Deprecated: Use Client.Target and target.Target.Read.
+func (c *Client) ReadSecret(ctx context.Context, path string) (map[string]interface{}, error) {
+	kv, err := c.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return kv.Read(ctx, path)
+}
+
+// ReadSecretVersion reads the current version of a secret from KV v2.
+// It returns nil if the secret does not exist.
+//
+// Deprecated: Use Client.Target and target.VersionedTarget.ReadVersion.
+func (c *Client) ReadSecretVersion(ctx context.Context, path string) (*SecretVersion, error) {
+	kv, err := c.versionedTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return kv.ReadVersion(ctx, path)
+}
+
+// SecretExists checks if a secret exists at the given path.
+//
+// Deprecated: Use Client.Target and target.Target.Exists.
+func (c *Client) SecretExists(ctx context.Context, path string) (bool, error) {
+	kv, err := c.Target(ctx)
+	if err != nil {
+		return false, err
+	}
+	return kv.Exists(ctx, path)
+}
+
+// MaxVersions returns the number of versions the KV v2 mount keeps per
+// secret.
+//
+// Deprecated: Use Client.Target and target.VersionedTarget.MaxVersions.
+func (c *Client) MaxVersions(ctx context.Context) (int, error) {
+	kv, err := c.versionedTarget(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return kv.MaxVersions(ctx)
+}
+
+// WriteCustomMetadata merges custom into the custom_metadata of a secret.
+//
+// Deprecated: Use Client.Target and target.VersionedTarget.WriteCustomMetadata.
+func (c *Client) WriteCustomMetadata(ctx context.Context, path string, custom map[string]string) error {
+	kv, err := c.versionedTarget(ctx)
+	if err != nil {
+		return err
+	}
+	return kv.WriteCustomMetadata(ctx, path, custom)
+}
+
+// versionedTarget returns the client's target if it is a KV v2 mount.
+func (c *Client) versionedTarget(ctx context.Context) (target.VersionedTarget, error) {
+	kv, err := c.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := kv.(target.VersionedTarget)
+	if !ok {
+		return nil, fmt.Errorf("mount %s is %s, not KV v2", c.mount, kv.Name())
+	}
+	return versioned, nil
+}
@@ -0,0 +1,112 @@
+package openbao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+)
+
+// TargetKVv1 is the registry name of the KV v1 target.
+const TargetKVv1 = "kv-v1"
+
+func init() {
+	// Register this target with the default registry
+	target.Register(TargetKVv1, NewKVv1)
+}
+
+// KVv1 implements the target.Target interface for a KV v1 mount. KV v1
+// keeps no version history or metadata: a write replaces the secret.
+type KVv1 struct {
+	conn  target.Conn
+	mount string
+}
+
+// NewKVv1 creates a KV v1 target for a mount.
+func NewKVv1(conn target.Conn, mount string) target.Target {
+	return &KVv1{conn: conn, mount: mount}
+}
+
+// Name returns the target identifier.
+func (t *KVv1) Name() string {
+	return TargetKVv1
+}
+
+// Exists checks if a secret exists at the given path.
+func (t *KVv1) Exists(ctx context.Context, path string) (bool, error) {
+	data, err := t.read(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check secret at %s: %w", path, err)
+	}
+	return data != nil, nil
+}
+
+// Read reads a secret. It returns nil if the secret does not exist.
+func (t *KVv1) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	data, err := t.read(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret from %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func (t *KVv1) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	var secret *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		secret, err = l.ReadWithContext(ctx, t.mount+"/"+path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+	return secret.Data, nil
+}
+
+// Write writes a secret, replacing any existing data.
+func (t *KVv1) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.WriteWithContext(ctx, t.mount+"/"+path, data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write secret to %s: %w", path, err)
+	}
+	return nil
+}
+
+// List lists the keys under path.
+func (t *KVv1) List(ctx context.Context, path string) ([]string, error) {
+	var secret *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		secret, err = l.ListWithContext(ctx, t.mount+"/"+path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets at %s: %w", path, err)
+	}
+	return KeysFromList(secret), nil
+}
+
+// Delete permanently deletes a secret.
+func (t *KVv1) Delete(ctx context.Context, path string) error {
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.DeleteWithContext(ctx, t.mount+"/"+path)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete secret at %s: %w", path, err)
+	}
+	return nil
+}
+
+// Health checks the OpenBao server health.
+func (t *KVv1) Health(ctx context.Context) error {
+	return t.conn.Health(ctx)
+}
@@ -0,0 +1,285 @@
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/secretdata"
+	"github.com/GlueOps/openbao-secrets-importer/pkg/target"
+)
+
+// TargetKVv2 is the registry name of the KV v2 target.
+const TargetKVv2 = "kv-v2"
+
+// DefaultMaxVersions is the number of versions KV v2 keeps when the mount's
+// max_versions is 0.
+const DefaultMaxVersions = 10
+
+func init() {
+	// Register this target with the default registry
+	target.Register(TargetKVv2, NewKVv2)
+}
+
+// KVv2 implements the target.VersionedTarget interface for a KV v2 mount.
+type KVv2 struct {
+	conn  target.Conn
+	mount string
+
+	configMu    sync.Mutex
+	maxVersions int // Mount's max_versions; 0 until read
+}
+
+// NewKVv2 creates a KV v2 target for a mount.
+func NewKVv2(conn target.Conn, mount string) target.Target {
+	return &KVv2{conn: conn, mount: mount}
+}
+
+// Name returns the target identifier.
+func (t *KVv2) Name() string {
+	return TargetKVv2
+}
+
+// Exists checks if a secret exists at the given path.
+func (t *KVv2) Exists(ctx context.Context, path string) (bool, error) {
+	secret, err := t.read(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check secret at %s: %w", path, err)
+	}
+	return secret != nil, nil
+}
+
+// Read reads the current version of a secret. It returns nil if the secret
+// does not exist or its current version is deleted.
+func (t *KVv2) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	version, err := t.ReadVersion(ctx, path)
+	if err != nil || version == nil {
+		return nil, err
+	}
+	return version.Data, nil
+}
+
+// ReadVersion reads the current version of a secret. It returns nil if the
+// secret does not exist.
+func (t *KVv2) ReadVersion(ctx context.Context, path string) (*target.Version, error) {
+	secret, err := t.read(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret from %s: %w", path, err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+
+	result := &target.Version{}
+	if data, ok := secret.Data["data"].(map[string]interface{}); ok {
+		result.Data = data
+	}
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		if n, ok := metadata["version"].(json.Number); ok {
+			if v, err := n.Int64(); err == nil {
+				result.Version = int(v)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// read reads the raw data endpoint of a secret. A deleted current version
+// still returns its metadata.
+func (t *KVv2) read(ctx context.Context, path string) (*api.Secret, error) {
+	var secret *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		secret, err = l.ReadWithContext(ctx, t.mount+"/data/"+path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// Write writes a new version of a secret.
+func (t *KVv2) Write(ctx context.Context, path string, data map[string]interface{}) error {
+	return t.write(ctx, path, map[string]interface{}{"data": data})
+}
+
+// WriteCAS writes a new version of a secret using Check-And-Set (CAS).
+// If cas is 0, the write will only succeed if the key doesn't exist.
+//
+// A write whose response was lost is retried with the same cas and then
+// fails with a mismatch although it landed. A mismatch therefore counts as
+// success if the current version is cas+1 and holds exactly data.
+func (t *KVv2) WriteCAS(ctx context.Context, path string, data map[string]interface{}, cas int) error {
+	err := t.write(ctx, path, map[string]interface{}{
+		"data":    data,
+		"options": map[string]interface{}{"cas": cas},
+	})
+	if !errors.Is(err, target.ErrCASMismatch) {
+		return err
+	}
+
+	current, rerr := t.ReadVersion(ctx, path)
+	if rerr != nil || current == nil || current.Version != cas+1 || !secretdata.Equal(current.Data, data) {
+		return err
+	}
+	return nil
+}
+
+func (t *KVv2) write(ctx context.Context, path string, body map[string]interface{}) error {
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.WriteWithContext(ctx, t.mount+"/data/"+path, body)
+		return err
+	})
+	if isCASMismatch(err) {
+		return fmt.Errorf("failed to write secret to %s: %w", path, target.ErrCASMismatch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write secret to %s: %w", path, err)
+	}
+	return nil
+}
+
+// isCASMismatch reports whether err is KV v2 rejecting a write because its
+// cas option did not match the current version.
+func isCASMismatch(err error) bool {
+	var respErr *api.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	for _, msg := range respErr.Errors {
+		if strings.Contains(msg, "check-and-set parameter did not match") {
+			return true
+		}
+	}
+	return false
+}
+
+// List lists the keys under path.
+func (t *KVv2) List(ctx context.Context, path string) ([]string, error) {
+	var secret *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		secret, err = l.ListWithContext(ctx, t.mount+"/metadata/"+path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets at %s: %w", path, err)
+	}
+	return KeysFromList(secret), nil
+}
+
+// Delete deletes the current version of a secret. Its history and metadata
+// are kept, so the deletion can be undone.
+func (t *KVv2) Delete(ctx context.Context, path string) error {
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.DeleteWithContext(ctx, t.mount+"/data/"+path)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete secret at %s: %w", path, err)
+	}
+	return nil
+}
+
+// Destroy permanently removes every version and the metadata of a secret.
+func (t *KVv2) Destroy(ctx context.Context, path string) error {
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.DeleteWithContext(ctx, t.mount+"/metadata/"+path)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to destroy secret at %s: %w", path, err)
+	}
+	return nil
+}
+
+// Health checks the OpenBao server health.
+func (t *KVv2) Health(ctx context.Context) error {
+	return t.conn.Health(ctx)
+}
+
+// MaxVersions returns the number of versions the mount keeps per secret,
+// read from <mount>/config on first use.
+func (t *KVv2) MaxVersions(ctx context.Context) (int, error) {
+	t.configMu.Lock()
+	defer t.configMu.Unlock()
+
+	if t.maxVersions > 0 {
+		return t.maxVersions, nil
+	}
+
+	var config *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		config, err = l.ReadWithContext(ctx, t.mount+"/config")
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read configuration of mount %s: %w", t.mount, err)
+	}
+
+	maxVersions := DefaultMaxVersions
+	if config != nil {
+		if n, ok := config.Data["max_versions"].(json.Number); ok {
+			if v, err := n.Int64(); err == nil && v > 0 {
+				maxVersions = int(v)
+			}
+		}
+	}
+
+	t.maxVersions = maxVersions
+	return maxVersions, nil
+}
+
+// WriteCustomMetadata merges custom into the custom_metadata of a secret.
+// Other metadata settings and existing custom_metadata keys are kept. New
+// keys that would take the merged custom_metadata over OpenBao's key limit
+// are dropped in sorted order.
+func (t *KVv2) WriteCustomMetadata(ctx context.Context, path string, custom map[string]string) error {
+	if len(custom) == 0 {
+		return nil
+	}
+
+	var metadata *api.Secret
+	err := t.conn.Do(ctx, func(l *api.Logical) error {
+		var err error
+		metadata, err = l.ReadWithContext(ctx, t.mount+"/metadata/"+path)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read metadata for %s: %w", path, err)
+	}
+	var existing map[string]interface{}
+	if metadata != nil {
+		existing, _ = metadata.Data["custom_metadata"].(map[string]interface{})
+	}
+	custom = fitCustomMetadata(custom, existing)
+
+	values := make(map[string]interface{}, len(custom))
+	for k, v := range custom {
+		values[k] = v
+	}
+
+	err = t.conn.Do(ctx, func(l *api.Logical) error {
+		_, err := l.JSONMergePatch(ctx, t.mount+"/metadata/"+path, map[string]interface{}{
+			"custom_metadata": values,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write metadata for %s: %w", path, err)
+	}
+
+	return nil
+}
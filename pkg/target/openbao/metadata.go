@@ -0,0 +1,121 @@
+package openbao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+// KV v2 custom_metadata limits enforced by OpenBao.
+const (
+	MaxCustomMetadataKeys        = 64
+	MaxCustomMetadataKeyLength   = 128
+	MaxCustomMetadataValueLength = 512
+)
+
+// DefaultMetadataPrefix is prepended to every custom_metadata key written
+// from source metadata.
+const DefaultMetadataPrefix = "source_"
+
+// CustomMetadata converts source metadata into KV v2 custom_metadata. Keys
+// are prefixed with prefix; tags become "<prefix>tag_<key>". Values are
+// truncated to OpenBao's limits, and keys are shortened with a hash suffix
+// (see shortenKey) so distinct long keys stay distinct. Tags beyond the key
+// limit are dropped in sorted order.
+func CustomMetadata(sourceName string, meta source.SecretMetadata, prefix string) map[string]string {
+	custom := make(map[string]string)
+	add := func(key, value string) {
+		if value == "" || len(custom) >= MaxCustomMetadataKeys {
+			return
+		}
+		custom[shortenKey(prefix+key)] = truncate(value, MaxCustomMetadataValueLength)
+	}
+	addTime := func(key string, t *time.Time) {
+		if t != nil {
+			add(key, t.UTC().Format(time.RFC3339))
+		}
+	}
+
+	add("name", sourceName)
+	add("id", meta.SourceID)
+	add("description", meta.Description)
+	add("content_type", meta.ContentType)
+	if meta.Enabled != nil {
+		add("enabled", strconv.FormatBool(*meta.Enabled))
+	}
+	addTime("created_at", meta.CreatedAt)
+	addTime("updated_at", meta.UpdatedAt)
+	addTime("expires_at", meta.ExpiresAt)
+
+	tagKeys := make([]string, 0, len(meta.Tags))
+	for k := range meta.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		add("tag_"+k, meta.Tags[k])
+	}
+
+	return custom
+}
+
+// shortenKey fits key into MaxCustomMetadataKeyLength. A longer key is
+// truncated and suffixed with "~" and a hash of the full key, so keys that
+// only differ after the limit do not collide.
+func shortenKey(key string) string {
+	if len(key) <= MaxCustomMetadataKeyLength {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	suffix := "~" + hex.EncodeToString(sum[:4])
+	return truncate(key, MaxCustomMetadataKeyLength-len(suffix)) + suffix
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 character.
+// Invalid UTF-8 is cut at the byte limit.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Back up over the continuation bytes of the character at the limit
+	for end := n; end > 0 && end > n-utf8.UTFMax; end-- {
+		if utf8.RuneStart(s[end]) {
+			return s[:end]
+		}
+	}
+	return s[:n]
+}
+
+// fitCustomMetadata drops keys of custom that are not in existing once the
+// merged custom_metadata would exceed MaxCustomMetadataKeys, keeping the
+// new keys in sorted order. Keys that are already present only change
+// their value and always fit.
+func fitCustomMetadata(custom map[string]string, existing map[string]interface{}) map[string]string {
+	var added []string
+	for k := range custom {
+		if _, ok := existing[k]; !ok {
+			added = append(added, k)
+		}
+	}
+	room := MaxCustomMetadataKeys - len(existing)
+	if len(added) <= room {
+		return custom
+	}
+
+	sort.Strings(added)
+	fitted := make(map[string]string, len(custom))
+	for k, v := range custom {
+		fitted[k] = v
+	}
+	for i, k := range added {
+		if i >= room {
+			delete(fitted, k)
+		}
+	}
+	return fitted
+}
@@ -0,0 +1,165 @@
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// MountConfig is the desired configuration of a KV v2 mount. Nil fields are
+// left as they are.
+type MountConfig struct {
+	// MaxVersions is the number of versions kept per secret (0: the default)
+	MaxVersions *int
+
+	// CASRequired makes every write require Check-And-Set
+	CASRequired *bool
+
+	// DeleteVersionAfter deletes versions older than this (0: never)
+	DeleteVersionAfter *time.Duration
+}
+
+// Empty reports whether the config changes nothing.
+func (m MountConfig) Empty() bool {
+	return m.MaxVersions == nil && m.CASRequired == nil && m.DeleteVersionAfter == nil
+}
+
+// EnsureMount enables a KV v2 secrets engine at the client's mount if there
+// is none and applies cfg to the mount's configuration. It returns a
+// description of every change made, which is empty if the mount was already
+// as desired.
+func (c *Client) EnsureMount(ctx context.Context, cfg MountConfig) ([]string, error) {
+	var changes []string
+
+	mount, err := c.readMount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if mount == nil {
+		err := c.Do(ctx, func(l *api.Logical) error {
+			_, err := l.WriteWithContext(ctx, "sys/mounts/"+c.mount, map[string]interface{}{
+				"type":    "kv",
+				"options": map[string]interface{}{"version": "2"},
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable KV v2 secrets engine at %s: %w", c.mount, err)
+		}
+		changes = append(changes, fmt.Sprintf("enabled KV v2 secrets engine at %s/", c.mount))
+	} else {
+		mountType, _ := mount["type"].(string)
+		if mountType != "kv" && mountType != "generic" {
+			return nil, fmt.Errorf("mount %s is not a KV mount (type %s)", c.mount, mountType)
+		}
+		options, _ := mount["options"].(map[string]interface{})
+		if version, _ := options["version"].(string); version != "2" && !cfg.Empty() {
+			return nil, fmt.Errorf("mount %s is KV v1; max_versions, cas_required and delete_version_after need KV v2", c.mount)
+		}
+	}
+
+	// The mount may have been created or reconfigured, so detect it again
+	c.targetMu.Lock()
+	c.target = nil
+	c.targetMu.Unlock()
+
+	if cfg.Empty() {
+		return changes, nil
+	}
+
+	configChanges, err := c.configureMount(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, configChanges...), nil
+}
+
+// readMount returns the sys/mounts entry of the client's mount, or nil if
+// nothing is mounted there.
+func (c *Client) readMount(ctx context.Context) (map[string]interface{}, error) {
+	var mounts *api.Secret
+	err := c.Do(ctx, func(l *api.Logical) error {
+		var err error
+		mounts, err = l.ReadWithContext(ctx, "sys/mounts")
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %w", err)
+	}
+	if mounts == nil {
+		return nil, nil
+	}
+
+	mount, _ := mounts.Data[strings.Trim(c.mount, "/")+"/"].(map[string]interface{})
+	return mount, nil
+}
+
+// configureMount writes the settings of cfg that differ from the mount's
+// current configuration.
+func (c *Client) configureMount(ctx context.Context, cfg MountConfig) ([]string, error) {
+	var current *api.Secret
+	err := c.Do(ctx, func(l *api.Logical) error {
+		var err error
+		current, err = l.ReadWithContext(ctx, c.mount+"/config")
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration of mount %s: %w", c.mount, err)
+	}
+	var data map[string]interface{}
+	if current != nil {
+		data = current.Data
+	}
+
+	var changes []string
+	update := make(map[string]interface{})
+
+	if cfg.MaxVersions != nil {
+		var have int64
+		if n, ok := data["max_versions"].(json.Number); ok {
+			have, _ = n.Int64()
+		}
+		if have != int64(*cfg.MaxVersions) {
+			update["max_versions"] = *cfg.MaxVersions
+			changes = append(changes, fmt.Sprintf("set max_versions of %s/ from %d to %d", c.mount, have, *cfg.MaxVersions))
+		}
+	}
+
+	if cfg.CASRequired != nil {
+		have, _ := data["cas_required"].(bool)
+		if have != *cfg.CASRequired {
+			update["cas_required"] = *cfg.CASRequired
+			changes = append(changes, fmt.Sprintf("set cas_required of %s/ from %t to %t", c.mount, have, *cfg.CASRequired))
+		}
+	}
+
+	if cfg.DeleteVersionAfter != nil {
+		var have time.Duration
+		if s, ok := data["delete_version_after"].(string); ok {
+			have, _ = time.ParseDuration(s)
+		}
+		if have != *cfg.DeleteVersionAfter {
+			update["delete_version_after"] = cfg.DeleteVersionAfter.String()
+			changes = append(changes, fmt.Sprintf("set delete_version_after of %s/ from %s to %s", c.mount, have, *cfg.DeleteVersionAfter))
+		}
+	}
+
+	if len(update) == 0 {
+		return nil, nil
+	}
+
+	err = c.Do(ctx, func(l *api.Logical) error {
+		_, err := l.WriteWithContext(ctx, c.mount+"/config", update)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure mount %s: %w", c.mount, err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,82 @@
+package target
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry manages available secret targets.
+type Registry struct {
+	mu      sync.RWMutex
+	targets map[string]TargetFactory
+}
+
+// NewRegistry creates a new target registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		targets: make(map[string]TargetFactory),
+	}
+}
+
+// Register adds a target factory to the registry.
+func (r *Registry) Register(name string, factory TargetFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets[name] = factory
+}
+
+// Get returns a new instance of the named target for a mount.
+func (r *Registry) Get(name string, conn Conn, mount string) (Target, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.targets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown target: %s", name)
+	}
+
+	return factory(conn, mount), nil
+}
+
+// List returns the names of all registered targets.
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.targets))
+	for name := range r.targets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Has checks if a target is registered.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.targets[name]
+	return ok
+}
+
+// DefaultRegistry is the global target registry.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a target factory to the default registry.
+func Register(name string, factory TargetFactory) {
+	DefaultRegistry.Register(name, factory)
+}
+
+// Get returns a new instance of the named target from the default registry.
+func Get(name string, conn Conn, mount string) (Target, error) {
+	return DefaultRegistry.Get(name, conn, mount)
+}
+
+// List returns the names of all registered targets in the default registry.
+func List() []string {
+	return DefaultRegistry.List()
+}
+
+// Has checks if a target is registered in the default registry.
+func Has(name string) bool {
+	return DefaultRegistry.Has(name)
+}
@@ -0,0 +1,87 @@
+// Package target defines the interface for secret targets.
+package target
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Target is a secrets engine mount that secrets are imported into.
+type Target interface {
+	// Name returns the target identifier (e.g., "kv-v2")
+	Name() string
+
+	// Exists reports whether a secret exists at path. A KV v2 secret whose
+	// current version is deleted still exists.
+	Exists(ctx context.Context, path string) (bool, error)
+
+	// Read returns the data of the secret at path, or nil if it does not
+	// exist or its current version is deleted
+	Read(ctx context.Context, path string) (map[string]interface{}, error)
+
+	// Write writes the data of the secret at path
+	Write(ctx context.Context, path string, data map[string]interface{}) error
+
+	// List returns the keys under path; keys ending in "/" are folders
+	List(ctx context.Context, path string) ([]string, error)
+
+	// Delete deletes the secret at path
+	Delete(ctx context.Context, path string) error
+
+	// Health checks that the server can serve requests
+	Health(ctx context.Context) error
+}
+
+// ErrCASMismatch is returned by VersionedTarget.WriteCAS when the current
+// version of the secret is not the expected one because it was written
+// concurrently.
+var ErrCASMismatch = errors.New("check-and-set version mismatch")
+
+// Version is the current version of a secret in a VersionedTarget.
+type Version struct {
+	// Data is the secret data, nil if the current version is deleted or destroyed
+	Data map[string]interface{}
+
+	// Version is the current version number, for use with WriteCAS
+	Version int
+}
+
+// VersionedTarget is a target that keeps a version history and metadata for
+// every secret, such as KV v2.
+type VersionedTarget interface {
+	Target
+
+	// ReadVersion returns the current version of the secret at path, or nil
+	// if it does not exist
+	ReadVersion(ctx context.Context, path string) (*Version, error)
+
+	// WriteCAS writes the secret at path only if its current version is cas
+	// (0: only if it does not exist), failing with ErrCASMismatch otherwise
+	WriteCAS(ctx context.Context, path string, data map[string]interface{}, cas int) error
+
+	// MaxVersions returns the number of versions kept per secret
+	MaxVersions(ctx context.Context) (int, error)
+
+	// WriteCustomMetadata merges custom into the custom metadata of the
+	// secret at path
+	WriteCustomMetadata(ctx context.Context, path string, custom map[string]string) error
+
+	// Destroy permanently removes every version and the metadata of the
+	// secret at path, unlike Delete which only deletes the current version
+	Destroy(ctx context.Context, path string) error
+}
+
+// Conn is the connection a target sends its requests on.
+type Conn interface {
+	// Do calls fn with the logical API of the connection's namespace. The
+	// call is authenticated, rate limited and retried like every request.
+	Do(ctx context.Context, fn func(*api.Logical) error) error
+
+	// Health checks the server health
+	Health(ctx context.Context) error
+}
+
+// TargetFactory creates a target for a mount on a connection.
+type TargetFactory func(conn Conn, mount string) Target
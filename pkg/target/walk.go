@@ -0,0 +1,32 @@
+package target
+
+import (
+	"context"
+	"strings"
+)
+
+// Walk returns the paths of all secrets below prefix, listing its folders
+// recursively. prefix is empty for the whole mount or a folder path ending
+// in "/"; the returned paths include it.
+func Walk(ctx context.Context, t Target, prefix string) ([]string, error) {
+	keys, err := t.List(ctx, strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, key := range keys {
+		child := prefix + key
+		if strings.HasSuffix(key, "/") {
+			below, err := Walk(ctx, t, child)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, below...)
+			continue
+		}
+		paths = append(paths, child)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,492 @@
+// Package transform provides key-level transformations of secret data,
+// applied between reading secrets and writing them.
+package transform
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gobwas/glob"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/source"
+)
+
+// Transform kinds accepted in transform specs and transforms files.
+const (
+	KindRename    = "rename"
+	KindDrop      = "drop"
+	KindFlatten   = "flatten"
+	KindUnflatten = "unflatten"
+	KindBase64    = "base64"
+)
+
+// DefaultSeparator joins nested keys when flattening and splits them when
+// unflattening.
+const DefaultSeparator = "."
+
+// Transform reshapes the data of a secret.
+type Transform interface {
+	// Apply returns the transformed data; data itself is not modified
+	Apply(data map[string]interface{}) (map[string]interface{}, error)
+
+	// String describes the transform
+	String() string
+}
+
+// Rule is a transform limited to secrets whose paths match one of its globs.
+type Rule struct {
+	Transform
+
+	// paths restricts the rule to matching secret paths; nil for all secrets
+	paths []glob.Glob
+}
+
+// Matches reports whether the rule applies to the secret at path.
+func (r Rule) Matches(path string) bool {
+	if len(r.paths) == 0 {
+		return true
+	}
+	for _, g := range r.paths {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules is an ordered list of rules. Each rule sees the output of the
+// previous one.
+type Rules []Rule
+
+// Apply transforms the data of secret and of its previous versions in place.
+func (r Rules) Apply(secret *source.Secret) error {
+	if len(r) == 0 {
+		return nil
+	}
+
+	data, err := r.apply(secret.Path, secret.Data)
+	if err != nil {
+		return fmt.Errorf("secret %s: %w", secret.Path, err)
+	}
+	secret.Data = data
+
+	for i, version := range secret.Versions {
+		data, err := r.apply(secret.Path, version.Data)
+		if err != nil {
+			return fmt.Errorf("secret %s version %s: %w", secret.Path, version.VersionID, err)
+		}
+		secret.Versions[i].Data = data
+	}
+
+	return nil
+}
+
+func (r Rules) apply(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	for _, rule := range r {
+		if !rule.Matches(path) {
+			continue
+		}
+		var err error
+		if data, err = rule.Apply(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", rule, err)
+		}
+	}
+	return data, nil
+}
+
+// Rename renames keys. Missing keys are ignored; renaming onto an existing
+// key is an error.
+type Rename struct {
+	keys map[string]string
+}
+
+// NewRename creates a rename transform from old to new key names.
+func NewRename(keys map[string]string) (*Rename, error) {
+	for from, to := range keys {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rename %q to %q", from, to)
+		}
+	}
+	return &Rename{keys: keys}, nil
+}
+
+// Apply renames the keys of data.
+func (t *Rename) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if to, ok := t.keys[k]; ok {
+			k = to
+		}
+		if _, exists := out[k]; exists {
+			return nil, fmt.Errorf("key %s already exists", k)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// String describes the transform.
+func (t *Rename) String() string {
+	pairs := make([]string, 0, len(t.keys))
+	for from, to := range t.keys {
+		pairs = append(pairs, from+"="+to)
+	}
+	sort.Strings(pairs)
+	return "rename " + strings.Join(pairs, ", ")
+}
+
+// Drop removes keys matching any of its globs.
+type Drop struct {
+	patterns []string
+	globs    []glob.Glob
+}
+
+// NewDrop creates a drop transform for key globs.
+func NewDrop(patterns []string) (*Drop, error) {
+	globs, err := compileGlobs(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &Drop{patterns: patterns, globs: globs}, nil
+}
+
+// Apply removes the matching keys of data.
+func (t *Drop) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if !matchAny(t.globs, k) {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// String describes the transform.
+func (t *Drop) String() string {
+	return "drop " + strings.Join(t.patterns, ", ")
+}
+
+// Flatten lifts nested JSON objects into top-level keys joined with a
+// separator: {"db": {"user": "x"}} becomes {"db.user": "x"}.
+type Flatten struct {
+	sep string
+}
+
+// NewFlatten creates a flatten transform.
+func NewFlatten(sep string) *Flatten {
+	if sep == "" {
+		sep = DefaultSeparator
+	}
+	return &Flatten{sep: sep}
+}
+
+// Apply flattens data.
+func (t *Flatten) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(data))
+	if err := t.flatten(out, "", data); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (t *Flatten) flatten(out map[string]interface{}, prefix string, data map[string]interface{}) error {
+	for k, v := range data {
+		key := prefix + k
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			if err := t.flatten(out, key+t.sep, nested); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, exists := out[key]; exists {
+			return fmt.Errorf("key %s already exists", key)
+		}
+		out[key] = v
+	}
+	return nil
+}
+
+// String describes the transform.
+func (t *Flatten) String() string {
+	return fmt.Sprintf("flatten %q", t.sep)
+}
+
+// Unflatten splits keys on a separator into nested JSON objects, reversing
+// Flatten.
+type Unflatten struct {
+	sep string
+}
+
+// NewUnflatten creates an unflatten transform.
+func NewUnflatten(sep string) *Unflatten {
+	if sep == "" {
+		sep = DefaultSeparator
+	}
+	return &Unflatten{sep: sep}
+}
+
+// Apply unflattens data.
+func (t *Unflatten) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	// Sorted keys make conflicts fail the same way on every run
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(data))
+	for _, k := range keys {
+		parts := strings.Split(k, t.sep)
+		node := out
+		for _, part := range parts[:len(parts)-1] {
+			child, exists := node[part]
+			if !exists {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			nested, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %s conflicts with key %s", k, part)
+			}
+			node = nested
+		}
+
+		last := parts[len(parts)-1]
+		if _, exists := node[last]; exists {
+			return nil, fmt.Errorf("key %s conflicts with another key", k)
+		}
+		node[last] = data[k]
+	}
+	return out, nil
+}
+
+// String describes the transform.
+func (t *Unflatten) String() string {
+	return fmt.Sprintf("unflatten %q", t.sep)
+}
+
+// Base64Decode decodes base64 string values of keys matching its globs, such
+// as the values BinaryData produces. The decoded value must be UTF-8 text.
+type Base64Decode struct {
+	patterns []string
+	globs    []glob.Glob
+}
+
+// NewBase64Decode creates a base64 decode transform for key globs.
+func NewBase64Decode(patterns []string) (*Base64Decode, error) {
+	globs, err := compileGlobs(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &Base64Decode{patterns: patterns, globs: globs}, nil
+}
+
+// Apply decodes the matching values of data.
+func (t *Base64Decode) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		out[k] = v
+		if !matchAny(t.globs, k) {
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a string", k)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is not valid base64: %w", k, err)
+		}
+		if !utf8.Valid(decoded) {
+			return nil, fmt.Errorf("key %s does not decode to UTF-8 text", k)
+		}
+		out[k] = string(decoded)
+	}
+	return out, nil
+}
+
+// String describes the transform.
+func (t *Base64Decode) String() string {
+	return "base64 " + strings.Join(t.patterns, ", ")
+}
+
+func compileGlobs(patterns []string) ([]glob.Glob, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no key patterns")
+	}
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, p := range patterns {
+		g, err := glob.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", p, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+func matchAny(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a transform spec as given on the command line. The transform
+// applies to every secret:
+//
+//	rename:<from>=<to>[,<from>=<to>...]
+//	drop:<key-glob>[,<key-glob>...]
+//	flatten[:<separator>]
+//	unflatten[:<separator>]
+//	base64:<key-glob>[,<key-glob>...]
+func Parse(spec string) (Rule, error) {
+	kind, arg, hasArg := strings.Cut(spec, ":")
+
+	var t Transform
+	var err error
+	switch kind {
+	case KindRename:
+		keys := make(map[string]string)
+		for _, pair := range strings.Split(arg, ",") {
+			from, to, ok := strings.Cut(pair, "=")
+			if !ok {
+				return Rule{}, fmt.Errorf("invalid transform %q (expected 'rename:<from>=<to>')", spec)
+			}
+			keys[from] = to
+		}
+		t, err = NewRename(keys)
+	case KindDrop:
+		t, err = NewDrop(splitList(arg))
+	case KindFlatten:
+		t = NewFlatten(arg)
+	case KindUnflatten:
+		t = NewUnflatten(arg)
+	case KindBase64:
+		t, err = NewBase64Decode(splitList(arg))
+	default:
+		return Rule{}, fmt.Errorf("invalid transform %q (expected rename, drop, flatten, unflatten or base64)", spec)
+	}
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid transform %q: %w", spec, err)
+	}
+	if !hasArg && (kind == KindRename || kind == KindDrop || kind == KindBase64) {
+		return Rule{}, fmt.Errorf("invalid transform %q: missing keys", spec)
+	}
+
+	return Rule{Transform: t}, nil
+}
+
+// ParseAll parses transform specs in order.
+func ParseAll(specs []string) (Rules, error) {
+	rules := make(Rules, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// file is the transforms file format. Each entry sets exactly one kind and
+// may be limited to secret paths matching globs:
+//
+//	transforms:
+//	  - rename: {DB_PASS: password, DB_USER: username}
+//	  - drop: ["_comment", "internal_*"]
+//	  - base64: [value]
+//	    paths: ["legacy/**"]
+//	  - flatten: "."
+type file struct {
+	Transforms []fileRule `yaml:"transforms"`
+}
+
+type fileRule struct {
+	Rename    map[string]string `yaml:"rename"`
+	Drop      []string          `yaml:"drop"`
+	Flatten   *string           `yaml:"flatten"`
+	Unflatten *string           `yaml:"unflatten"`
+	Base64    []string          `yaml:"base64"`
+	Paths     []string          `yaml:"paths"`
+}
+
+// LoadFile reads rules from a YAML (or JSON) transforms file.
+func LoadFile(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transforms file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse transforms file %s: %w", path, err)
+	}
+
+	rules := make(Rules, 0, len(f.Transforms))
+	for i, fr := range f.Transforms {
+		rule, err := fr.rule()
+		if err != nil {
+			return nil, fmt.Errorf("transforms file %s: transform %d: %w", path, i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// rule builds the rule described by a transforms file entry.
+func (fr fileRule) rule() (Rule, error) {
+	kinds := 0
+	for _, set := range []bool{fr.Rename != nil, fr.Drop != nil, fr.Flatten != nil, fr.Unflatten != nil, fr.Base64 != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return Rule{}, fmt.Errorf("expected exactly one of rename, drop, flatten, unflatten or base64")
+	}
+
+	var rule Rule
+	var err error
+	switch {
+	case fr.Rename != nil:
+		rule.Transform, err = NewRename(fr.Rename)
+	case fr.Drop != nil:
+		rule.Transform, err = NewDrop(fr.Drop)
+	case fr.Flatten != nil:
+		rule.Transform = NewFlatten(*fr.Flatten)
+	case fr.Unflatten != nil:
+		rule.Transform = NewUnflatten(*fr.Unflatten)
+	default:
+		rule.Transform, err = NewBase64Decode(fr.Base64)
+	}
+	if err != nil {
+		return Rule{}, err
+	}
+
+	for _, p := range fr.Paths {
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid path pattern %q: %w", p, err)
+		}
+		rule.paths = append(rule.paths, g)
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,196 @@
+// Package verify compares the contents of OpenBao with an export file and
+// describes the differences without revealing secret values.
+package verify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GlueOps/openbao-secrets-importer/pkg/plan"
+)
+
+// Status is the verification outcome for a single secret.
+type Status string
+
+const (
+	// StatusOK means the destination matches the export file.
+	StatusOK Status = "ok"
+
+	// StatusMissing means the destination secret does not exist.
+	StatusMissing Status = "missing"
+
+	// StatusMismatch means keys or values differ.
+	StatusMismatch Status = "mismatch"
+
+	// StatusError means the destination secret could not be read.
+	StatusError Status = "error"
+)
+
+// Report is the machine-readable verification report.
+type Report struct {
+	// Input is the export file that was verified
+	Input string `json:"input"`
+
+	// Address is the OpenBao server address
+	Address string `json:"address"`
+
+	// Mount is the KV v2 mount path
+	Mount string `json:"mount"`
+
+	// Namespace is the OpenBao namespace
+	Namespace string `json:"namespace,omitempty"`
+
+	// PathPrefix is the prefix prepended to all secret paths
+	PathPrefix string `json:"path_prefix,omitempty"`
+
+	// VerifiedAt is when the verification was performed
+	VerifiedAt time.Time `json:"verified_at"`
+
+	// Summary counts the results per status
+	Summary Summary `json:"summary"`
+
+	// Results lists one entry per secret in the export file
+	Results []Result `json:"results"`
+}
+
+// Summary counts verification results.
+type Summary struct {
+	Total    int `json:"total"`
+	OK       int `json:"ok"`
+	Missing  int `json:"missing"`
+	Mismatch int `json:"mismatch"`
+	Error    int `json:"error"`
+}
+
+// Result is the verification result for a single secret.
+type Result struct {
+	// Path is the destination path in OpenBao
+	Path string `json:"path"`
+
+	// SourcePath is the secret path in the export file
+	SourcePath string `json:"source_path"`
+
+	// Status is the verification outcome
+	Status Status `json:"status"`
+
+	// MissingKeys are keys in the export file but not in OpenBao
+	MissingKeys []string `json:"missing_keys,omitempty"`
+
+	// ExtraKeys are keys in OpenBao but not in the export file
+	ExtraKeys []string `json:"extra_keys,omitempty"`
+
+	// Mismatches are keys present on both sides with different values
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+
+	// Error is the read error for StatusError
+	Error string `json:"error,omitempty"`
+}
+
+// Mismatch identifies a differing value by the fingerprints of both sides.
+// Fingerprints are only comparable within one report.
+type Mismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected_hash"`
+	Actual   string `json:"actual_hash"`
+}
+
+// Compare verifies the current destination data against the data from the
+// export file, fingerprinting differing values with h. current is nil if the
+// destination secret does not exist.
+func Compare(h *Hasher, path, sourcePath string, expected, current map[string]interface{}) Result {
+	result := Result{
+		Path:       path,
+		SourcePath: sourcePath,
+		Status:     StatusOK,
+	}
+
+	if current == nil {
+		result.Status = StatusMissing
+		return result
+	}
+
+	var changed []string
+	result.MissingKeys, result.ExtraKeys, changed = plan.Diff(expected, current)
+	for _, k := range changed {
+		result.Mismatches = append(result.Mismatches, Mismatch{
+			Key:      k,
+			Expected: h.Hash(expected[k]),
+			Actual:   h.Hash(current[k]),
+		})
+	}
+
+	if len(result.MissingKeys) > 0 || len(result.ExtraKeys) > 0 || len(result.Mismatches) > 0 {
+		result.Status = StatusMismatch
+	}
+
+	return result
+}
+
+// Hasher fingerprints values with HMAC-SHA256 under a random key that is
+// generated per run and never stored. Reports can show that values differ
+// without revealing them, and unlike a plain hash, the fingerprint of a
+// short or guessable value cannot be brute-forced offline.
+type Hasher struct {
+	key []byte
+}
+
+// NewHasher creates a Hasher with a new random key.
+func NewHasher() (*Hasher, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate fingerprint key: %w", err)
+	}
+	return &Hasher{key: key}, nil
+}
+
+// Hash returns a short HMAC-SHA256 fingerprint of a value's JSON encoding.
+func (h *Hasher) Hash(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", v))
+	}
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(data)
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Add appends a result and updates the summary.
+func (r *Report) Add(result Result) {
+	r.Results = append(r.Results, result)
+	r.Summary.Total++
+	switch result.Status {
+	case StatusOK:
+		r.Summary.OK++
+	case StatusMissing:
+		r.Summary.Missing++
+	case StatusMismatch:
+		r.Summary.Mismatch++
+	case StatusError:
+		r.Summary.Error++
+	}
+}
+
+// Drifted returns the number of secrets that did not verify.
+func (r *Report) Drifted() int {
+	return r.Summary.Total - r.Summary.OK
+}
+
+// Write writes the report as JSON to the specified path.
+func (r *Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}